@@ -0,0 +1,501 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: blinkt.proto
+
+package blinktrpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type SubscribeStateRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeStateRequest) Reset()         { *m = SubscribeStateRequest{} }
+func (m *SubscribeStateRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeStateRequest) ProtoMessage()    {}
+func (*SubscribeStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{0}
+}
+func (m *SubscribeStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeStateRequest.Unmarshal(m, b)
+}
+func (m *SubscribeStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeStateRequest.Marshal(b, m, deterministic)
+}
+func (dst *SubscribeStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeStateRequest.Merge(dst, src)
+}
+func (m *SubscribeStateRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeStateRequest.Size(m)
+}
+func (m *SubscribeStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeStateRequest proto.InternalMessageInfo
+
+type Resource struct {
+	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Color string `protobuf:"bytes,2,opt,name=color" json:"color,omitempty"`
+	// slot is the LED showing the resource, or -1 if it is hidden.
+	Slot                 int32    `protobuf:"varint,3,opt,name=slot" json:"slot,omitempty"`
+	Visible              bool     `protobuf:"varint,4,opt,name=visible" json:"visible,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Resource) Reset()         { *m = Resource{} }
+func (m *Resource) String() string { return proto.CompactTextString(m) }
+func (*Resource) ProtoMessage()    {}
+func (*Resource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{1}
+}
+func (m *Resource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Resource.Unmarshal(m, b)
+}
+func (m *Resource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Resource.Marshal(b, m, deterministic)
+}
+func (dst *Resource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Resource.Merge(dst, src)
+}
+func (m *Resource) XXX_Size() int {
+	return xxx_messageInfo_Resource.Size(m)
+}
+func (m *Resource) XXX_DiscardUnknown() {
+	xxx_messageInfo_Resource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Resource proto.InternalMessageInfo
+
+func (m *Resource) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Resource) GetColor() string {
+	if m != nil {
+		return m.Color
+	}
+	return ""
+}
+
+func (m *Resource) GetSlot() int32 {
+	if m != nil {
+		return m.Slot
+	}
+	return 0
+}
+
+func (m *Resource) GetVisible() bool {
+	if m != nil {
+		return m.Visible
+	}
+	return false
+}
+
+type State struct {
+	Resources            []*Resource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *State) Reset()         { *m = State{} }
+func (m *State) String() string { return proto.CompactTextString(m) }
+func (*State) ProtoMessage()    {}
+func (*State) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{2}
+}
+func (m *State) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_State.Unmarshal(m, b)
+}
+func (m *State) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_State.Marshal(b, m, deterministic)
+}
+func (dst *State) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_State.Merge(dst, src)
+}
+func (m *State) XXX_Size() int {
+	return xxx_messageInfo_State.Size(m)
+}
+func (m *State) XXX_DiscardUnknown() {
+	xxx_messageInfo_State.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_State proto.InternalMessageInfo
+
+func (m *State) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+type SetBrightnessRequest struct {
+	Brightness           float64  `protobuf:"fixed64,1,opt,name=brightness" json:"brightness,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBrightnessRequest) Reset()         { *m = SetBrightnessRequest{} }
+func (m *SetBrightnessRequest) String() string { return proto.CompactTextString(m) }
+func (*SetBrightnessRequest) ProtoMessage()    {}
+func (*SetBrightnessRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{3}
+}
+func (m *SetBrightnessRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBrightnessRequest.Unmarshal(m, b)
+}
+func (m *SetBrightnessRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBrightnessRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetBrightnessRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBrightnessRequest.Merge(dst, src)
+}
+func (m *SetBrightnessRequest) XXX_Size() int {
+	return xxx_messageInfo_SetBrightnessRequest.Size(m)
+}
+func (m *SetBrightnessRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBrightnessRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBrightnessRequest proto.InternalMessageInfo
+
+func (m *SetBrightnessRequest) GetBrightness() float64 {
+	if m != nil {
+		return m.Brightness
+	}
+	return 0
+}
+
+type SetBrightnessResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBrightnessResponse) Reset()         { *m = SetBrightnessResponse{} }
+func (m *SetBrightnessResponse) String() string { return proto.CompactTextString(m) }
+func (*SetBrightnessResponse) ProtoMessage()    {}
+func (*SetBrightnessResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{4}
+}
+func (m *SetBrightnessResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBrightnessResponse.Unmarshal(m, b)
+}
+func (m *SetBrightnessResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBrightnessResponse.Marshal(b, m, deterministic)
+}
+func (dst *SetBrightnessResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBrightnessResponse.Merge(dst, src)
+}
+func (m *SetBrightnessResponse) XXX_Size() int {
+	return xxx_messageInfo_SetBrightnessResponse.Size(m)
+}
+func (m *SetBrightnessResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBrightnessResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBrightnessResponse proto.InternalMessageInfo
+
+type SetPausedRequest struct {
+	Paused               bool     `protobuf:"varint,1,opt,name=paused" json:"paused,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPausedRequest) Reset()         { *m = SetPausedRequest{} }
+func (m *SetPausedRequest) String() string { return proto.CompactTextString(m) }
+func (*SetPausedRequest) ProtoMessage()    {}
+func (*SetPausedRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{5}
+}
+func (m *SetPausedRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPausedRequest.Unmarshal(m, b)
+}
+func (m *SetPausedRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPausedRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetPausedRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPausedRequest.Merge(dst, src)
+}
+func (m *SetPausedRequest) XXX_Size() int {
+	return xxx_messageInfo_SetPausedRequest.Size(m)
+}
+func (m *SetPausedRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPausedRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPausedRequest proto.InternalMessageInfo
+
+func (m *SetPausedRequest) GetPaused() bool {
+	if m != nil {
+		return m.Paused
+	}
+	return false
+}
+
+type SetPausedResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPausedResponse) Reset()         { *m = SetPausedResponse{} }
+func (m *SetPausedResponse) String() string { return proto.CompactTextString(m) }
+func (*SetPausedResponse) ProtoMessage()    {}
+func (*SetPausedResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_blinkt_30b206b46b41366a, []int{6}
+}
+func (m *SetPausedResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPausedResponse.Unmarshal(m, b)
+}
+func (m *SetPausedResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPausedResponse.Marshal(b, m, deterministic)
+}
+func (dst *SetPausedResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPausedResponse.Merge(dst, src)
+}
+func (m *SetPausedResponse) XXX_Size() int {
+	return xxx_messageInfo_SetPausedResponse.Size(m)
+}
+func (m *SetPausedResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPausedResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPausedResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*SubscribeStateRequest)(nil), "blinkt.SubscribeStateRequest")
+	proto.RegisterType((*Resource)(nil), "blinkt.Resource")
+	proto.RegisterType((*State)(nil), "blinkt.State")
+	proto.RegisterType((*SetBrightnessRequest)(nil), "blinkt.SetBrightnessRequest")
+	proto.RegisterType((*SetBrightnessResponse)(nil), "blinkt.SetBrightnessResponse")
+	proto.RegisterType((*SetPausedRequest)(nil), "blinkt.SetPausedRequest")
+	proto.RegisterType((*SetPausedResponse)(nil), "blinkt.SetPausedResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Board service
+
+type BoardClient interface {
+	// SubscribeState sends the resources on the board now, then again after
+	// every render.
+	SubscribeState(ctx context.Context, in *SubscribeStateRequest, opts ...grpc.CallOption) (Board_SubscribeStateClient, error)
+	// SetBrightness changes the brightness of the board, between 0 and 1.
+	SetBrightness(ctx context.Context, in *SetBrightnessRequest, opts ...grpc.CallOption) (*SetBrightnessResponse, error)
+	// SetPaused pauses or resumes the display.
+	SetPaused(ctx context.Context, in *SetPausedRequest, opts ...grpc.CallOption) (*SetPausedResponse, error)
+}
+
+type boardClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBoardClient(cc *grpc.ClientConn) BoardClient {
+	return &boardClient{cc}
+}
+
+func (c *boardClient) SubscribeState(ctx context.Context, in *SubscribeStateRequest, opts ...grpc.CallOption) (Board_SubscribeStateClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Board_serviceDesc.Streams[0], c.cc, "/blinkt.Board/SubscribeState", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &boardSubscribeStateClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Board_SubscribeStateClient interface {
+	Recv() (*State, error)
+	grpc.ClientStream
+}
+
+type boardSubscribeStateClient struct {
+	grpc.ClientStream
+}
+
+func (x *boardSubscribeStateClient) Recv() (*State, error) {
+	m := new(State)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *boardClient) SetBrightness(ctx context.Context, in *SetBrightnessRequest, opts ...grpc.CallOption) (*SetBrightnessResponse, error) {
+	out := new(SetBrightnessResponse)
+	err := grpc.Invoke(ctx, "/blinkt.Board/SetBrightness", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *boardClient) SetPaused(ctx context.Context, in *SetPausedRequest, opts ...grpc.CallOption) (*SetPausedResponse, error) {
+	out := new(SetPausedResponse)
+	err := grpc.Invoke(ctx, "/blinkt.Board/SetPaused", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Board service
+
+type BoardServer interface {
+	// SubscribeState sends the resources on the board now, then again after
+	// every render.
+	SubscribeState(*SubscribeStateRequest, Board_SubscribeStateServer) error
+	// SetBrightness changes the brightness of the board, between 0 and 1.
+	SetBrightness(context.Context, *SetBrightnessRequest) (*SetBrightnessResponse, error)
+	// SetPaused pauses or resumes the display.
+	SetPaused(context.Context, *SetPausedRequest) (*SetPausedResponse, error)
+}
+
+func RegisterBoardServer(s *grpc.Server, srv BoardServer) {
+	s.RegisterService(&_Board_serviceDesc, srv)
+}
+
+func _Board_SubscribeState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BoardServer).SubscribeState(m, &boardSubscribeStateServer{stream})
+}
+
+type Board_SubscribeStateServer interface {
+	Send(*State) error
+	grpc.ServerStream
+}
+
+type boardSubscribeStateServer struct {
+	grpc.ServerStream
+}
+
+func (x *boardSubscribeStateServer) Send(m *State) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Board_SetBrightness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBrightnessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BoardServer).SetBrightness(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blinkt.Board/SetBrightness",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BoardServer).SetBrightness(ctx, req.(*SetBrightnessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Board_SetPaused_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPausedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BoardServer).SetPaused(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blinkt.Board/SetPaused",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BoardServer).SetPaused(ctx, req.(*SetPausedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Board_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "blinkt.Board",
+	HandlerType: (*BoardServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetBrightness",
+			Handler:    _Board_SetBrightness_Handler,
+		},
+		{
+			MethodName: "SetPaused",
+			Handler:    _Board_SetPaused_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeState",
+			Handler:       _Board_SubscribeState_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "blinkt.proto",
+}
+
+func init() { proto.RegisterFile("blinkt.proto", fileDescriptor_blinkt_30b206b46b41366a) }
+
+var fileDescriptor_blinkt_30b206b46b41366a = []byte{
+	// 313 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0xcf, 0x4f, 0xc2, 0x30,
+	0x18, 0x4d, 0x85, 0x21, 0xfb, 0x10, 0x83, 0x9f, 0xa8, 0x95, 0x88, 0x59, 0x76, 0x5a, 0x3c, 0x10,
+	0x83, 0x89, 0x5e, 0x09, 0x67, 0x0f, 0xa6, 0xbb, 0x79, 0x92, 0x8d, 0x2f, 0xba, 0xb0, 0xd0, 0xd9,
+	0x76, 0x26, 0xfe, 0xa9, 0xfe, 0x37, 0x86, 0x76, 0x9b, 0x2c, 0xc1, 0x5b, 0xdf, 0x8f, 0xbd, 0xbe,
+	0xd7, 0x0c, 0x4e, 0x92, 0x3c, 0xdb, 0x6e, 0xcc, 0xac, 0x50, 0xd2, 0x48, 0xec, 0x39, 0x14, 0x5e,
+	0xc1, 0x45, 0x5c, 0x26, 0x3a, 0x55, 0x59, 0x42, 0xb1, 0x59, 0x19, 0x12, 0xf4, 0x59, 0x92, 0x36,
+	0xe1, 0x1b, 0xf4, 0x05, 0x69, 0x59, 0xaa, 0x94, 0x70, 0x04, 0x9d, 0x0d, 0x7d, 0x73, 0x16, 0xb0,
+	0xc8, 0x17, 0xbb, 0x23, 0x8e, 0xc1, 0x4b, 0x65, 0x2e, 0x15, 0x3f, 0xb2, 0x9c, 0x03, 0x88, 0xd0,
+	0xd5, 0xb9, 0x34, 0xbc, 0x13, 0xb0, 0xc8, 0x13, 0xf6, 0x8c, 0x1c, 0x8e, 0xbf, 0x32, 0x9d, 0x25,
+	0x39, 0xf1, 0x6e, 0xc0, 0xa2, 0xbe, 0xa8, 0x61, 0xf8, 0x04, 0x9e, 0xbd, 0x11, 0x67, 0xe0, 0xab,
+	0xea, 0x2a, 0xcd, 0x59, 0xd0, 0x89, 0x06, 0xf3, 0xd1, 0xac, 0x6a, 0x5b, 0x77, 0x10, 0x7f, 0x96,
+	0xf0, 0x11, 0xc6, 0x31, 0x99, 0xa5, 0xca, 0xde, 0x3f, 0xcc, 0x96, 0xb4, 0xae, 0x2a, 0xe3, 0x2d,
+	0x40, 0xd2, 0x90, 0xb6, 0x2d, 0x13, 0x7b, 0x8c, 0xdd, 0xda, 0xfe, 0x4e, 0x17, 0x72, 0xab, 0x29,
+	0xbc, 0x83, 0x51, 0x4c, 0xe6, 0x65, 0x55, 0x6a, 0x5a, 0xd7, 0x61, 0x97, 0xd0, 0x2b, 0x2c, 0x61,
+	0x83, 0xfa, 0xa2, 0x42, 0xe1, 0x39, 0x9c, 0xed, 0x79, 0x5d, 0xc0, 0xfc, 0x87, 0x81, 0xb7, 0x94,
+	0x2b, 0xb5, 0xc6, 0x05, 0x9c, 0xb6, 0xdf, 0x13, 0xa7, 0xf5, 0x94, 0x83, 0xef, 0x3c, 0x19, 0x36,
+	0xf2, 0x8e, 0xbd, 0x67, 0xf8, 0x0c, 0xc3, 0x56, 0x4b, 0xbc, 0x69, 0x1c, 0x07, 0x46, 0x4f, 0xa6,
+	0xff, 0xa8, 0xae, 0x19, 0x2e, 0xc0, 0x6f, 0xea, 0x22, 0xdf, 0xf3, 0xb6, 0xd6, 0x4e, 0xae, 0x0f,
+	0x28, 0x2e, 0x61, 0x39, 0x78, 0xf5, 0x9d, 0xa6, 0x8a, 0x34, 0xe9, 0xd9, 0xbf, 0xe7, 0xe1, 0x77,
+	0x00, 0x2f, 0x47, 0x4a, 0x54, 0x4d, 0x02, 0x00, 0x00,
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package blinktrpc serves the Board service of blinkt.proto, so a remote
+// tool can follow what a controller shows and change its brightness or pause
+// it.
+package blinktrpc
+
+//go:generate protoc --go_out=plugins=grpc:. blinkt.proto
+
+import (
+	"context"
+
+	"github.com/elafargue/blinkt-k8s-controller/controller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Board is the part of a controller the service drives. *controller.ControllerObj
+// implements it.
+type Board interface {
+	Subscribe() (updates <-chan []controller.ResourceView, cancel func())
+	SetBrightness(brightness float64)
+	SetPaused(paused bool)
+}
+
+// Server implements BoardServer on top of a Board.
+type Server struct {
+	board Board
+}
+
+// NewServer returns a Server for board.
+func NewServer(board Board) *Server {
+	return &Server{board}
+}
+
+// Register creates a gRPC server serving the Board service for board.
+func Register(board Board, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	RegisterBoardServer(s, NewServer(board))
+	return s
+}
+
+func (s *Server) SubscribeState(req *SubscribeStateRequest, stream Board_SubscribeStateServer) error {
+	updates, cancel := s.board.Subscribe()
+	defer cancel()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case views, ok := <-updates:
+			if !ok {
+				return status.Errorf(codes.ResourceExhausted, "fell too far behind the board")
+			}
+			if err := stream.Send(newState(views)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) SetBrightness(ctx context.Context, req *SetBrightnessRequest) (*SetBrightnessResponse, error) {
+	if req.Brightness < 0 || req.Brightness > 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid brightness %v: must be between 0 and 1", req.Brightness)
+	}
+	s.board.SetBrightness(req.Brightness)
+	return &SetBrightnessResponse{}, nil
+}
+
+func (s *Server) SetPaused(ctx context.Context, req *SetPausedRequest) (*SetPausedResponse, error) {
+	s.board.SetPaused(req.Paused)
+	return &SetPausedResponse{}, nil
+}
+
+func newState(views []controller.ResourceView) *State {
+	state := &State{Resources: make([]*Resource, 0, len(views))}
+	for _, v := range views {
+		state.Resources = append(state.Resources, &Resource{
+			Key:     v.Key,
+			Color:   v.Color,
+			Slot:    int32(v.Slot),
+			Visible: v.Visible,
+		})
+	}
+	return state
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"math"
+	"sort"
+)
+
+type bucket struct {
+	color string
+	count int
+	leds  int
+}
+
+// buckets groups the resources by color, largest group first.
+func (o *ControllerObj) buckets() []bucket {
+	index := map[string]int{}
+	var buckets []bucket
+	for _, r := range o.resourceList {
+		i, ok := index[r.color]
+		if !ok {
+			i = len(buckets)
+			index[r.color] = i
+			buckets = append(buckets, bucket{color: r.color})
+		}
+		buckets[i].count++
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		if buckets[i].count != buckets[j].count {
+			return buckets[i].count > buckets[j].count
+		}
+		return buckets[i].color < buckets[j].color
+	})
+	return buckets
+}
+
+// renderBlend lines up every resource, grouped by color, and spreads them
+// evenly over the LEDs. Each LED shows the average of its resources' colors.
+func (o *ControllerObj) renderBlend(buckets []bucket, lit []bool) {
+	total := 0
+	for _, b := range buckets {
+		total += b.count
+	}
+	colors := make([][]string, len(lit))
+	j := 0
+	for _, b := range buckets {
+		for i := 0; i < b.count; i++ {
+			slot := j * len(lit) / total
+			colors[slot] = append(colors[slot], b.color)
+			j++
+		}
+	}
+	for slot, c := range colors {
+		if len(c) > 0 {
+			o.set(o.led(slot), averageColors(c), o.brightness)
+			lit[slot] = true
+		}
+	}
+}
+
+// allocate shares slots between the buckets in proportion to their counts
+// using the largest remainder method. Every bucket gets at least one LED as
+// long as there are enough to go around, taken from the largest bucket.
+func allocate(buckets []bucket, slots int) {
+	total := 0
+	for _, b := range buckets {
+		total += b.count
+	}
+	if total == 0 {
+		return
+	}
+	remainders := make([]float64, len(buckets))
+	left := slots
+	for i := range buckets {
+		quota := float64(buckets[i].count) * float64(slots) / float64(total)
+		buckets[i].leds = int(math.Floor(quota))
+		remainders[i] = quota - math.Floor(quota)
+		left -= buckets[i].leds
+	}
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; i < left; i++ {
+		buckets[order[i]].leds++
+	}
+	for i := range buckets {
+		if i >= slots || buckets[i].leds > 0 {
+			continue
+		}
+		largest := 0
+		for j := range buckets {
+			if buckets[j].leds > buckets[largest].leds {
+				largest = j
+			}
+		}
+		if buckets[largest].leds > 1 {
+			buckets[largest].leds--
+			buckets[i].leds++
+		}
+	}
+}
+
+// renderAggregate lights LEDs color by color, largest group first.
+func (o *ControllerObj) renderAggregate(lit []bool) {
+	buckets := o.buckets()
+	if o.BlendOverflow && len(buckets) > len(lit) {
+		o.renderBlend(buckets, lit)
+	} else {
+		allocate(buckets, len(lit))
+		slot := 0
+		for _, b := range buckets {
+			for i := 0; i < b.leds; i++ {
+				o.set(o.led(slot), b.color, o.brightness)
+				lit[slot] = true
+				slot++
+			}
+		}
+	}
+	for i := range o.resourceList {
+		o.resourceList[i].state = unchanged
+	}
+}
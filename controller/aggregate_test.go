@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elafargue/blinkt"
+)
+
+func TestAverageColors(t *testing.T) {
+	tests := []struct {
+		colors []string
+		want   string
+	}{
+		{[]string{"FF0000", "00FF00"}, "808000"},
+		{[]string{"FF0000", "FF0000", "0000FF"}, "AA0055"},
+		{[]string{"FFFFFF"}, "FFFFFF"},
+		{[]string{"FF0000", "not a color"}, "FF0000"},
+		{nil, blinkt.Off},
+	}
+	for _, test := range tests {
+		if got := averageColors(test.colors); got != test.want {
+			t.Errorf("averageColors(%v) = %s, want %s", test.colors, got, test.want)
+		}
+	}
+}
+
+func TestRenderBlend(t *testing.T) {
+	o, d := newTestController(t, WithLEDCount(2), WithAggregateMode(true), WithBlendOverflow(true))
+	o.Add("a", "FF0000")
+	o.Add("b", "FF0000")
+	o.Add("c", "00FF00")
+	o.Add("d", "0000FF")
+	// Red takes the first LED; blue and green, tied, share the second.
+	if got, want := d.colors()[:2], []string{"FF0000", "008080"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LEDs %v, want %v", got, want)
+	}
+}
+
+func TestRenderBlendRedGreen(t *testing.T) {
+	o, d := newTestController(t, WithLEDCount(1), WithAggregateMode(true), WithBlendOverflow(true))
+	o.Add("a", "FF0000")
+	o.Add("b", "00FF00")
+	if got := d.colors()[0]; got != "808000" {
+		t.Errorf("LED 0 is %s, want red and green blended into 808000", got)
+	}
+}
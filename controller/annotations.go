@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ColorAnnotation pins an object to a fixed RRGGBB color regardless of the
+// watch's color function.
+const ColorAnnotation = "blinkt.apprenda.io/color"
+
+// SlotAnnotation pins an object to an LED, counted among the LEDs available
+// to resources. When two objects ask for the same slot the one with the
+// smaller key gets it and the other is placed like an unpinned object.
+const SlotAnnotation = "blinkt.apprenda.io/slot"
+
+// PriorityAnnotation orders objects on the strip: higher priorities come
+// first, and objects without it have priority 0. Pinned objects keep their
+// slot regardless, so priority only orders the objects placed around them.
+const PriorityAnnotation = "blinkt.apprenda.io/priority"
+
+func annotation(obj interface{}, name string) (string, bool) {
+	m, ok := unwrap(obj).(metav1.Object)
+	if !ok {
+		return "", false
+	}
+	value, ok := m.GetAnnotations()[name]
+	return value, ok
+}
+
+func (o *ControllerObj) annotationColor(obj interface{}) (string, bool) {
+	color, ok := annotation(obj, ColorAnnotation)
+	if !ok {
+		return "", false
+	}
+	color, err := parseColor(color)
+	if err != nil {
+		o.logger.Warn("Ignoring color annotation", "error", err)
+		return "", false
+	}
+	return color, true
+}
+
+// annotationPriority returns the priority requested by obj, or 0.
+func (o *ControllerObj) annotationPriority(obj interface{}) int {
+	value, ok := annotation(obj, PriorityAnnotation)
+	if !ok {
+		return 0
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		o.logger.Warn("Ignoring priority annotation", "value", value)
+		return 0
+	}
+	return priority
+}
+
+// annotationSlot returns the slot requested by obj, or -1.
+func (o *ControllerObj) annotationSlot(obj interface{}) int {
+	value, ok := annotation(obj, SlotAnnotation)
+	if !ok {
+		return -1
+	}
+	slot, err := strconv.Atoi(value)
+	if err != nil || slot < 0 || slot >= o.slots() {
+		o.logger.Warn("Ignoring slot annotation", "value", value, "slots", o.slots())
+		return -1
+	}
+	return slot
+}
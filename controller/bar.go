@@ -0,0 +1,37 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "math"
+
+// renderBar lights the first LEDs in proportion to BarFunc's value.
+func (o *ControllerObj) renderBar(lit []bool) {
+	var value float64
+	if err := safely("bar function", func() { value = o.BarFunc() }); err != nil {
+		o.logger.Warn("Drawing an empty bar", "error", err)
+		value = 0
+	}
+	if math.IsNaN(value) {
+		value = 0
+	}
+	n := int(math.Round(math.Max(0, math.Min(1, value)) * float64(len(lit))))
+	for slot := 0; slot < n; slot++ {
+		o.set(o.led(slot), o.BarColor, o.brightness)
+		lit[slot] = true
+	}
+	for i := range o.resourceList {
+		o.resourceList[i].state = unchanged
+	}
+}
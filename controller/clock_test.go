@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+func TestFlashFollowsClock(t *testing.T) {
+	clock := newFakeClock()
+	o, d := newTestController(t, WithClock(clock), WithFlash(2, 50*time.Millisecond))
+	h := watchHandler(o, constant(blinkt.Red))
+	d.reset()
+	clock.sleeps()
+	h.OnAdd(pod("default", "a"))
+
+	want := []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	if got := clock.sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("slept %v, want %v", got, want)
+	}
+	if got, want := d.sent("set", 0), []string{blinkt.Red, blinkt.Off, blinkt.Red, blinkt.Off, blinkt.Red}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LED 0 was set to %v, want %v", got, want)
+	}
+	if got := d.colors()[0]; got != blinkt.Red {
+		t.Errorf("LED 0 is %s after flashing, want %s", got, blinkt.Red)
+	}
+}
+
+func TestPersistFollowsClock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blinkt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "state.json")
+	clock := newFakeClock()
+	o, _ := newTestController(t, WithClock(clock), WithFlash(0, 0), WithStateFile(file))
+
+	watchHandler(o, constant(blinkt.Red)).OnAdd(pod("default", "a"))
+	clock.Advance(stateFileDelay - time.Millisecond)
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("state file written before the delay: %v", err)
+	}
+	clock.Advance(time.Millisecond)
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("state file not written once the delay passed: %v", err)
+	}
+}
+
+func TestTickerFollowsClock(t *testing.T) {
+	clock := newFakeClock()
+	ticker := clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-ticker.Chan():
+		t.Fatal("ticked early")
+	default:
+	}
+	clock.Advance(time.Millisecond)
+	select {
+	case <-ticker.Chan():
+	default:
+		t.Fatal("did not tick after its period")
+	}
+}
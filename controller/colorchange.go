@@ -0,0 +1,44 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+// colorChanged must be called with resourceLock held. It queues the change
+// for notifyColorChange, dropping it if too many are already waiting so the
+// hook never holds up rendering.
+func (o *ControllerObj) colorChanged(key, oldColor, newColor string) {
+	if o.OnColorChange == nil || oldColor == newColor {
+		return
+	}
+	select {
+	case o.colorChanges <- Event{EventUpdated, key, oldColor, newColor}:
+	default:
+		o.logger.Debug("Dropping color change", "key", key)
+	}
+}
+
+// notifyColorChange calls OnColorChange without holding resourceLock, in
+// the order the changes happened.
+func (o *ControllerObj) notifyColorChange(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case e := <-o.colorChanges:
+			if err := safely("color change function", func() { o.OnColorChange(e.Key, e.OldColor, e.NewColor) }); err != nil {
+				o.logger.Warn("Ignoring color change hook failure", "key", e.Key, "error", err)
+			}
+		}
+	}
+}
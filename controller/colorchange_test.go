@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+func TestColorChangeFunc(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		changes [][3]string
+		o       *ControllerObj
+	)
+	o, _ = newTestController(t, WithFlash(0, 0), WithColorChangeFunc(func(key, oldColor, newColor string) {
+		// Taking the lock would deadlock if the hook ran with it held.
+		o.Brightness()
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, [3]string{key, oldColor, newColor})
+	}))
+	o.Run(nil)
+	defer o.Stop()
+
+	o.Add("a", blinkt.Red)
+	o.Update("a", blinkt.Blue)
+	o.Update("a", blinkt.Blue)
+	o.Add("b", blinkt.Red)
+	o.Update("a", "00FF00")
+
+	want := [][3]string{{"a", blinkt.Red, blinkt.Blue}, {"a", blinkt.Blue, "00FF00"}}
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(changes) >= len(want)
+	})
+	// Leave time for a spurious call the adds or the unchanged update made.
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("color changes %v, want %v", changes, want)
+	}
+}
+
+func TestPanickingColorChangeFunc(t *testing.T) {
+	calls := make(chan string, 2)
+	o, _ := newTestController(t, WithFlash(0, 0), WithColorChangeFunc(func(key, oldColor, newColor string) {
+		calls <- newColor
+		if newColor == blinkt.Blue {
+			panic("boom")
+		}
+	}))
+	o.Run(nil)
+	defer o.Stop()
+
+	o.Add("a", blinkt.Red)
+	o.Update("a", blinkt.Blue)
+	if got := <-calls; got != blinkt.Blue {
+		t.Fatalf("new color %s, want %s", got, blinkt.Blue)
+	}
+	o.Update("a", blinkt.Red)
+	select {
+	case got := <-calls:
+		if got != blinkt.Red {
+			t.Errorf("new color %s, want %s", got, blinkt.Red)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the color change function was not called again after it panicked")
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elafargue/blinkt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Colors used by PodPhaseColorFunc. Reassign any of them to change the
+// color of a single phase.
+var (
+	PodRunningColor   = "00FF00"
+	PodPendingColor   = "FFFF00"
+	PodFailedColor    = blinkt.Red
+	PodSucceededColor = blinkt.Blue
+	PodUnknownColor   = "FFFFFF"
+)
+
+// PodPhaseColorFunc colors a *v1.Pod by its phase.
+func PodPhaseColorFunc(obj interface{}) string {
+	pod, ok := unwrap(obj).(*v1.Pod)
+	if !ok {
+		return PodUnknownColor
+	}
+	switch pod.Status.Phase {
+	case v1.PodRunning:
+		return PodRunningColor
+	case v1.PodPending:
+		return PodPendingColor
+	case v1.PodFailed:
+		return PodFailedColor
+	case v1.PodSucceeded:
+		return PodSucceededColor
+	}
+	return PodUnknownColor
+}
+
+// PodRestartPulseRate is a PulseRateFunc that makes a *v1.Pod pulse faster
+// the more its containers have restarted, from once every two seconds after
+// the first restart. Pods that never restarted stay steady.
+func PodRestartPulseRate(obj interface{}) time.Duration {
+	pod, ok := unwrap(obj).(*v1.Pod)
+	if !ok {
+		return 0
+	}
+	restarts := int32(0)
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	if restarts <= 0 {
+		return 0
+	}
+	rate := time.Second / time.Duration(restarts)
+	if rate < pulseTick {
+		rate = pulseTick
+	}
+	return rate
+}
+
+// ChainColorFuncs tries funcs in order and returns the first color one of
+// them gives. A func abstains by returning an empty color, or by failing, and
+// the next one is tried. When they all abstain the result is blinkt.Off, so
+// the LED turns off without a warning, or the first error if any failed.
+func ChainColorFuncs(funcs ...ColorFuncE) ColorFuncE {
+	return func(obj interface{}) (string, error) {
+		var first error
+		for _, f := range funcs {
+			color, err := f(obj)
+			if err != nil {
+				if first == nil {
+					first = err
+				}
+				continue
+			}
+			if color != "" {
+				return color, nil
+			}
+		}
+		if first != nil {
+			return "", first
+		}
+		return blinkt.Off, nil
+	}
+}
+
+// Colors used by DeploymentColorFunc and StatefulSetColorFunc.
+var (
+	ReplicasReadyColor   = "00FF00"
+	ReplicasPartialColor = "FFFF00"
+	ReplicasDownColor    = blinkt.Red
+	ReplicasUnknownColor = "FFFFFF"
+)
+
+// DeploymentColorFunc colors a *appsv1.Deployment by how many of the
+// replicas it wants are ready: all of them, some, or none.
+func DeploymentColorFunc(obj interface{}) string {
+	deployment, ok := unwrap(obj).(*appsv1.Deployment)
+	if !ok {
+		return ReplicasUnknownColor
+	}
+	return replicasColor(deployment.Status.ReadyReplicas, deployment.Spec.Replicas)
+}
+
+// StatefulSetColorFunc colors a *appsv1.StatefulSet like DeploymentColorFunc
+// colors a deployment.
+func StatefulSetColorFunc(obj interface{}) string {
+	set, ok := unwrap(obj).(*appsv1.StatefulSet)
+	if !ok {
+		return ReplicasUnknownColor
+	}
+	return replicasColor(set.Status.ReadyReplicas, set.Spec.Replicas)
+}
+
+// replicasColor compares ready to the desired replicas, which default to 1
+// when unset like they do in the API server. A workload scaled to zero has
+// all of its replicas ready.
+func replicasColor(ready int32, replicas *int32) string {
+	desired := int32(1)
+	if replicas != nil {
+		desired = *replicas
+	}
+	switch {
+	case ready >= desired:
+		return ReplicasReadyColor
+	case ready <= 0:
+		return ReplicasDownColor
+	}
+	return ReplicasPartialColor
+}
+
+// Colors used by NodeConditionColorFunc.
+var (
+	NodeReadyColor    = "00FF00"
+	NodeNotReadyColor = blinkt.Red
+	NodeUnknownColor  = "FFFF00"
+	NodePressureColor = "FFBF00"
+)
+
+var pressureTaints = map[string]bool{
+	"node.kubernetes.io/memory-pressure": true,
+	"node.kubernetes.io/disk-pressure":   true,
+}
+
+// NodeConditionColorFunc colors a *v1.Node by its Ready condition. A ready
+// node under memory or disk pressure shows NodePressureColor instead.
+func NodeConditionColorFunc(obj interface{}) string {
+	node, ok := unwrap(obj).(*v1.Node)
+	if !ok {
+		return NodeUnknownColor
+	}
+	ready := v1.ConditionUnknown
+	pressure := false
+	for _, c := range node.Status.Conditions {
+		switch c.Type {
+		case v1.NodeReady:
+			ready = c.Status
+		case v1.NodeMemoryPressure, v1.NodeDiskPressure:
+			pressure = pressure || c.Status == v1.ConditionTrue
+		}
+	}
+	for _, t := range node.Spec.Taints {
+		pressure = pressure || pressureTaints[t.Key]
+	}
+	switch ready {
+	case v1.ConditionTrue:
+		if pressure {
+			return NodePressureColor
+		}
+		return NodeReadyColor
+	case v1.ConditionFalse:
+		return NodeNotReadyColor
+	}
+	return NodeUnknownColor
+}
+
+// Colors at the ends and middle of the UtilizationColorFunc gradient.
+var (
+	UtilizationLowColor  = "00FF00"
+	UtilizationMidColor  = "FFFF00"
+	UtilizationHighColor = "FF0000"
+)
+
+// UtilizationColorFunc shades an LED from green at 0 through yellow at 0.5 to
+// red at 1 by the utilization get returns for the object. Values outside
+// [0, 1] are clamped.
+func UtilizationColorFunc(get func(obj interface{}) float64) ColorFunc {
+	return func(obj interface{}) string {
+		return utilizationColor(get(unwrap(obj)))
+	}
+}
+
+func utilizationColor(u float64) string {
+	if math.IsNaN(u) {
+		u = 0
+	}
+	u = math.Max(0, math.Min(1, u))
+	if u < 0.5 {
+		return blendColors(UtilizationLowColor, UtilizationMidColor, u*2)
+	}
+	return blendColors(UtilizationMidColor, UtilizationHighColor, (u-0.5)*2)
+}
+
+// unwrap returns the last known state of an object the informer lost track
+// of, or obj itself. The controller unwraps objects before handing them to
+// any user function, so those never see a tombstone.
+func unwrap(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+// parseHex splits an RRGGBB color into its channels.
+func parseHex(color string) (r, g, b uint8, err error) {
+	if len(color) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid color %q", color)
+	}
+	var rgb uint64
+	rgb, err = strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q", color)
+	}
+	return uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), nil
+}
+
+// parseColor normalizes the color forms a ColorFunc may return to the RRGGBB
+// strings the strip understands: RRGGBB (including the blinkt constants),
+// #RRGGBB, and hsv(h,s,v) with h in degrees, wrapped around the circle, and s
+// and v in [0, 1].
+func parseColor(color string) (string, error) {
+	c := strings.TrimSpace(color)
+	if strings.HasPrefix(c, "hsv(") && strings.HasSuffix(c, ")") {
+		parts := strings.Split(c[len("hsv("):len(c)-1], ",")
+		if len(parts) != 3 {
+			return "", fmt.Errorf("invalid color %q", color)
+		}
+		var hsv [3]float64
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || (i > 0 && (v < 0 || v > 1)) {
+				return "", fmt.Errorf("invalid color %q", color)
+			}
+			hsv[i] = v
+		}
+		return hsvToColor(math.Mod(hsv[0], 360), hsv[1], hsv[2]), nil
+	}
+	c = strings.TrimPrefix(c, "#")
+	if _, _, _, err := parseHex(c); err != nil {
+		return "", fmt.Errorf("invalid color %q", color)
+	}
+	return strings.ToUpper(c), nil
+}
+
+// blendColors interpolates between two RRGGBB colors, t going from 0 (from)
+// to 1 (to). Unparseable colors jump straight to to.
+func blendColors(from, to string, t float64) string {
+	r1, g1, b1, err1 := parseHex(from)
+	r2, g2, b2, err2 := parseHex(to)
+	if err1 != nil || err2 != nil {
+		return to
+	}
+	lerp := func(a, b uint8) int {
+		return int(math.Round(float64(a) + t*(float64(b)-float64(a))))
+	}
+	return fmt.Sprintf("%02X%02X%02X", lerp(r1, r2), lerp(g1, g2), lerp(b1, b2))
+}
+
+// averageColors averages the channels of RRGGBB colors, skipping the ones
+// that don't parse.
+func averageColors(colors []string) string {
+	var r, g, b, n int
+	for _, color := range colors {
+		cr, cg, cb, err := parseHex(color)
+		if err != nil {
+			continue
+		}
+		r, g, b, n = r+int(cr), g+int(cg), b+int(cb), n+1
+	}
+	if n == 0 {
+		return blinkt.Off
+	}
+	avg := func(sum int) int {
+		return int(math.Round(float64(sum) / float64(n)))
+	}
+	return fmt.Sprintf("%02X%02X%02X", avg(r), avg(g), avg(b))
+}
+
+// hsvToColor converts a hue in degrees and a saturation and value in [0, 1]
+// to a hex color.
+func hsvToColor(h, s, v float64) string {
+	h = math.Mod(math.Mod(h, 360)+360, 360)
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := v - c
+	return fmt.Sprintf("%02X%02X%02X", int(math.Round(255*(r+m))), int(math.Round(255*(g+m))), int(math.Round(255*(b+m))))
+}
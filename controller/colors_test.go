@@ -0,0 +1,206 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/elafargue/blinkt"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func node(taints []string, conditions ...v1.NodeCondition) *v1.Node {
+	n := &v1.Node{}
+	n.Status.Conditions = conditions
+	for _, key := range taints {
+		n.Spec.Taints = append(n.Spec.Taints, v1.Taint{Key: key, Effect: v1.TaintEffectNoSchedule})
+	}
+	return n
+}
+
+func condition(t v1.NodeConditionType, status v1.ConditionStatus) v1.NodeCondition {
+	return v1.NodeCondition{Type: t, Status: status}
+}
+
+func TestNodeConditionColorFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want string
+	}{
+		{"ready", node(nil, condition(v1.NodeReady, v1.ConditionTrue)), NodeReadyColor},
+		{"not ready", node(nil, condition(v1.NodeReady, v1.ConditionFalse)), NodeNotReadyColor},
+		{"unknown", node(nil, condition(v1.NodeReady, v1.ConditionUnknown)), NodeUnknownColor},
+		{"no conditions", node(nil), NodeUnknownColor},
+		{"memory pressure", node(nil, condition(v1.NodeReady, v1.ConditionTrue), condition(v1.NodeMemoryPressure, v1.ConditionTrue)), NodePressureColor},
+		{"disk pressure", node(nil, condition(v1.NodeDiskPressure, v1.ConditionTrue), condition(v1.NodeReady, v1.ConditionTrue)), NodePressureColor},
+		{"pressure cleared", node(nil, condition(v1.NodeReady, v1.ConditionTrue), condition(v1.NodeMemoryPressure, v1.ConditionFalse)), NodeReadyColor},
+		{"memory pressure taint", node([]string{"node.kubernetes.io/memory-pressure"}, condition(v1.NodeReady, v1.ConditionTrue)), NodePressureColor},
+		{"disk pressure taint", node([]string{"node.kubernetes.io/disk-pressure"}, condition(v1.NodeReady, v1.ConditionTrue)), NodePressureColor},
+		{"other taint", node([]string{"dedicated"}, condition(v1.NodeReady, v1.ConditionTrue)), NodeReadyColor},
+		{"not ready under pressure", node([]string{"node.kubernetes.io/disk-pressure"}, condition(v1.NodeReady, v1.ConditionFalse)), NodeNotReadyColor},
+		{"unknown under pressure", node(nil, condition(v1.NodeMemoryPressure, v1.ConditionTrue)), NodeUnknownColor},
+		{"not a node", &v1.Pod{}, NodeUnknownColor},
+	}
+	for _, test := range tests {
+		if got := NodeConditionColorFunc(test.obj); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		color string
+		want  string
+	}{
+		{"ff0000", "FF0000"},
+		{" #00ff00 ", "00FF00"},
+		{"hsv(0,1,1)", "FF0000"},
+		{"hsv(120, 1, 1)", "00FF00"},
+		{"hsv(480,1,1)", "00FF00"},
+		{"hsv(-120,1,1)", "0000FF"},
+		{"hsv(1e300,1,1)", hsvToColor(math.Mod(1e300, 360), 1, 1)},
+	}
+	for _, test := range tests {
+		got, err := parseColor(test.color)
+		if err != nil || got != test.want {
+			t.Errorf("parseColor(%q) = %q, %v, want %q", test.color, got, err, test.want)
+		}
+	}
+	for _, color := range []string{"", "red", "#12345", "hsv(0,1)", "hsv(NaN,1,1)", "hsv(Inf,1,1)", "hsv(-Inf,1,1)", "hsv(0,NaN,1)", "hsv(0,1,2)"} {
+		if got, err := parseColor(color); err == nil {
+			t.Errorf("parseColor(%q) = %q, want an error", color, got)
+		}
+	}
+}
+
+func TestChainColorFuncs(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	abstain := func(interface{}) (string, error) { return "", nil }
+	fail := func(err error) ColorFuncE {
+		return func(interface{}) (string, error) { return "", err }
+	}
+	color := func(c string) ColorFuncE {
+		return func(interface{}) (string, error) { return c, nil }
+	}
+	tests := []struct {
+		name    string
+		funcs   []ColorFuncE
+		want    string
+		wantErr error
+	}{
+		{"first match", []ColorFuncE{color("FF0000"), color("00FF00")}, "FF0000", nil},
+		{"abstain falls through", []ColorFuncE{abstain, color("00FF00")}, "00FF00", nil},
+		{"error falls through", []ColorFuncE{fail(errFirst), color("00FF00")}, "00FF00", nil},
+		{"all abstain", []ColorFuncE{abstain, abstain}, blinkt.Off, nil},
+		{"first error kept", []ColorFuncE{abstain, fail(errFirst), fail(errSecond)}, "", errFirst},
+		{"empty chain", nil, blinkt.Off, nil},
+	}
+	for _, test := range tests {
+		got, err := ChainColorFuncs(test.funcs...)(&v1.Pod{})
+		if got != test.want || err != test.wantErr {
+			t.Errorf("%s: got %q, %v, want %q, %v", test.name, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func replicas(n int32) *int32 {
+	return &n
+}
+
+func deployment(ready int32, desired *int32) *appsv1.Deployment {
+	d := &appsv1.Deployment{}
+	d.Spec.Replicas = desired
+	d.Status.ReadyReplicas = ready
+	return d
+}
+
+func TestDeploymentColorFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want string
+	}{
+		{"ready", deployment(3, replicas(3)), ReplicasReadyColor},
+		{"partial", deployment(1, replicas(3)), ReplicasPartialColor},
+		{"down", deployment(0, replicas(3)), ReplicasDownColor},
+		{"scaled to zero", deployment(0, replicas(0)), ReplicasReadyColor},
+		{"unset replicas ready", deployment(1, nil), ReplicasReadyColor},
+		{"unset replicas down", deployment(0, nil), ReplicasDownColor},
+		{"surge", deployment(4, replicas(3)), ReplicasReadyColor},
+		{"tombstone", cache.DeletedFinalStateUnknown{Key: "default/d", Obj: deployment(1, replicas(2))}, ReplicasPartialColor},
+		{"not a deployment", &v1.Pod{}, ReplicasUnknownColor},
+	}
+	for _, test := range tests {
+		if got := DeploymentColorFunc(test.obj); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func statefulSet(ready int32, desired *int32) *appsv1.StatefulSet {
+	set := &appsv1.StatefulSet{}
+	set.Spec.Replicas = desired
+	set.Status.ReadyReplicas = ready
+	return set
+}
+
+func TestStatefulSetColorFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want string
+	}{
+		{"ready", statefulSet(3, replicas(3)), ReplicasReadyColor},
+		{"partial", statefulSet(2, replicas(3)), ReplicasPartialColor},
+		{"down", statefulSet(0, replicas(3)), ReplicasDownColor},
+		{"scaled to zero", statefulSet(0, replicas(0)), ReplicasReadyColor},
+		{"unset replicas ready", statefulSet(1, nil), ReplicasReadyColor},
+		{"unset replicas down", statefulSet(0, nil), ReplicasDownColor},
+		{"tombstone", cache.DeletedFinalStateUnknown{Key: "default/s", Obj: statefulSet(0, replicas(2))}, ReplicasDownColor},
+		{"not a stateful set", deployment(1, replicas(1)), ReplicasUnknownColor},
+	}
+	for _, test := range tests {
+		if got := StatefulSetColorFunc(test.obj); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUtilizationColor(t *testing.T) {
+	tests := []struct {
+		u    float64
+		want string
+	}{
+		{0, "00FF00"},
+		{0.25, "80FF00"},
+		{0.5, "FFFF00"},
+		{0.75, "FF8000"},
+		{1.0, "FF0000"},
+		{-1, "00FF00"},
+		{3, "FF0000"},
+		{math.NaN(), "00FF00"},
+	}
+	for _, test := range tests {
+		if got := utilizationColor(test.u); got != test.want {
+			t.Errorf("utilizationColor(%v) = %s, want %s", test.u, got, test.want)
+		}
+	}
+}
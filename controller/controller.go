@@ -15,15 +15,25 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
+	"math"
+	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/elafargue/blinkt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
 )
@@ -35,136 +45,1915 @@ const (
 	unchanged = iota
 )
 
+const maxLEDCount = 8
+
+// pixelCounter is implemented by drivers that address more or fewer LEDs
+// than a single Blinkt.
+type pixelCounter interface {
+	Pixels() int
+}
+
+// defaultBrightness marks a resource that uses the controller's brightness.
+const defaultBrightness = -1
+
+var stateNames = []string{"added", "updated", "deleted", "unchanged"}
+
+// ColorFunc returns the color of a resource's LED as RRGGBB, #RRGGBB or
+// hsv(h,s,v). Anything else turns the LED off.
 type ColorFunc func(obj interface{}) string
 
+// ColorFuncE is a ColorFunc that can fail. When it returns an error the event
+// is logged and skipped, leaving the resource's LED unchanged.
+type ColorFuncE func(obj interface{}) (string, error)
+
+// BrightnessFunc returns the brightness of a single resource's LED. Values are
+// clamped into [0, 1].
+type BrightnessFunc func(obj interface{}) float64
+
+// KeyFunc identifies the resource an object belongs to.
+type KeyFunc func(obj interface{}) (string, error)
+
+// WeightFunc returns how many consecutive LEDs a resource takes. Weights
+// below 1 count as 1, and no resource takes more LEDs than the strip has.
+type WeightFunc func(obj interface{}) int
+
+// LessFunc orders the watched objects on the strip. Resources set through
+// Add have a nil object.
+type LessFunc func(a, b interface{}) bool
+
+type BlinktDriver interface {
+	Set(pixel int, color string, brightness float64) error
+	Show() error
+	Cleanup(color string, brightness float64) error
+}
+
+// newInformer is replaced by tests to get at the event handlers.
+var newInformer = cache.NewInformer
+
 type Controller interface {
-	Watch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc)
+	Watch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc, stopCh <-chan struct{})
+	WatchE(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFuncE, stopCh <-chan struct{})
+	WatchWithBrightness(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc, brightnessFunc BrightnessFunc, stopCh <-chan struct{})
+	AddWatch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc)
+	WatchWithInformer(informer cache.SharedIndexInformer, colorFunc ColorFunc, stopCh <-chan struct{})
+	Run(stopCh <-chan struct{})
+	WatchContext(ctx context.Context, listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc)
+	HandleSignals() <-chan struct{}
+	MetricsHandler() http.Handler
+	StateHandler() http.Handler
+	StreamHandler() http.Handler
+	RegisterLegend(legend map[string]string)
+	Legend() map[string]string
+	LegendHandler() http.Handler
+	Events() <-chan Event
+	Refresh() error
+	SetColorFunc(colorFunc ColorFunc)
+	SetBrightness(brightness float64)
+	Brightness() float64
+	SetPaused(paused bool)
+	TestPattern(ctx context.Context) error
+	Subscribe() (updates <-chan []ResourceView, cancel func())
+	Snapshot() []ResourceView
+	IsVisible(key string) bool
+	HasSynced() bool
+	HealthHandler() http.Handler
+	Add(key, color string)
+	Update(key, color string)
+	Delete(key string)
+	Stop()
 	Cleanup()
 }
 
+// ControllerObj drives the strip. Once it runs, brightness, FlashCount,
+// FlashDuration, RefreshInterval and CleanupColor may be changed by a config
+// reload and must only be accessed with resourceLock held.
 type ControllerObj struct {
-	brightness   float64
-	resourceList []resource
-	resourceLock *sync.Mutex
-	blinkt       blinkt.Blinkt
+	brightness          float64
+	ledCount            int
+	ScrollInterval      time.Duration
+	StableSlots         bool
+	GroupByNamespace    bool
+	FlashCount          int
+	FlashDuration       time.Duration
+	MinFlashInterval    time.Duration
+	FlashColor          string
+	FlashPatterns       map[string]FlashPattern
+	DeleteLinger        time.Duration
+	DeleteColor         string
+	MonochromeColor     string
+	IntensityFunc       BrightnessFunc
+	SortByActivity      bool
+	ErrorColor          string
+	Less                LessFunc
+	IdleAnimation       bool
+	HeartbeatSlot       int
+	HeartbeatColor      string
+	CleanupColor        string
+	BlankOnCleanup      bool
+	DryRun              bool
+	MaxResources        int
+	Eviction            EvictionPolicy
+	KeyFunc             KeyFunc
+	Selector            labels.Selector
+	OverflowColor       string
+	logger              Logger
+	TransitionDuration  time.Duration
+	AggregateMode       bool
+	BlendOverflow       bool
+	InvertSlots         bool
+	InvertColor         string
+	BarFunc             func() float64
+	BarColor            string
+	Gamma               float64
+	StateFile           string
+	ConfigFile          string
+	PauseOnSignal       bool
+	PauseBlank          bool
+	AlertColors         []string
+	NightBrightness     float64
+	NightStart          string
+	NightEnd            string
+	Location            *time.Location
+	HealthThreshold     time.Duration
+	RepaintInterval     time.Duration
+	ResyncJitter        float64
+	OnOverflow          func(hidden []string)
+	OnColorChange       func(key, oldColor, newColor string)
+	StartupAnimation    bool
+	WeightFunc          WeightFunc
+	PulseRateFunc       PulseRateFunc
+	TickInterval        time.Duration
+	TickFunc            TickFunc
+	IndexMapper         IndexMapper
+	Reverse             bool
+	TracerProvider      trace.TracerProvider
+	SkipUnchanged       bool
+	clock               Clock
+	overflowBlink       bool
+	paused              bool
+	quiet               bool
+	alerts              map[string]bool
+	alertBlink          bool
+	nightStart          time.Duration
+	nightEnd            time.Duration
+	dimmer              *dimBlinkt
+	driver              *lockedBlinkt
+	hidden              []string
+	hiddenCh            chan []string
+	evicted             map[resourceID]bool
+	legend              map[string]string
+	added               int
+	jitter              float64
+	RefreshInterval     time.Duration
+	dirty               bool
+	stale               bool
+	positions           []int
+	widths              []int
+	occupants           []int
+	shadow              map[int]pixel
+	pending             bool
+	markers             []marker
+	extent              int
+	informers           []cache.Controller
+	syncing             int
+	synced              bool
+	subscribers         map[chan boardState]struct{}
+	snapshotSubscribers map[chan []ResourceView]struct{}
+	events              chan Event
+	eventsClosed        bool
+	saveTimer           Timer
+	reloaded            chan struct{}
+	sources             int
+	evaluators          map[int]func(obj interface{})
+	colorFunc           ColorFuncE
+	tracer              trace.Tracer
+	watchErrors         map[int]error
+	errorShown          bool
+	colorChanges        chan Event
+	scrollOffset        int
+	resourceList        []resource
+	resourceLock        *sync.Mutex
+	blinkt              BlinktDriver
+	signalOnce          sync.Once
+	signalStopCh        chan struct{}
+	backgroundOnce      sync.Once
+	stopCh              chan struct{}
+	stopOnce            sync.Once
+	running             sync.WaitGroup
+	metrics             *metrics
 }
 
+type Option func(o *ControllerObj)
+
 type resource struct {
-	key   string
-	color string
-	state int
-}
-
-func NewController(brightness float64) Controller {
-	return &ControllerObj{
-		brightness,
-		[]resource{},
-		&sync.Mutex{},
-		blinkt.NewBlinkt(blinkt.Blue, brightness),
-	}
-}
-
-func (o *ControllerObj) Watch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc) {
-	_, controller := cache.NewInformer(
-		listWatch,
-		objType,
-		resyncPeriod,
-		cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				o.resourceLock.Lock()
-				defer o.resourceLock.Unlock()
-				key := keyFunc(obj)
-				color := colorFunc(obj)
-				r := resource{key, color, added}
-				log.Print("Adding ", r.key, "...\n")
-				o.resourceList = append(o.resourceList, r)
-				o.updateBlinkt()
-			},
-			UpdateFunc: func(oldObj, newObj interface{}) {
-				o.resourceLock.Lock()
-				defer o.resourceLock.Unlock()
-				key := keyFunc(newObj)
-				color := colorFunc(newObj)
-				r := o.getResource(key)
-				if color == r.color {
-					return
-				}
-				log.Print("Updating ", r.key, "...\n")
-				r.color = color
-				r.state = updated
-				o.updateBlinkt()
-			},
-			DeleteFunc: func(obj interface{}) {
-				o.resourceLock.Lock()
-				defer o.resourceLock.Unlock()
-				key := keyFunc(obj)
-				r := o.getResource(key)
-				log.Print("Deleting ", r.key, "...\n")
-				r.state = deleted
-				o.updateBlinkt()
-			},
+	source          int
+	key             string
+	color           string
+	prevColor       string
+	brightness      float64
+	state           int
+	slot            int
+	pin             int
+	shown           bool
+	overflowChecked bool
+	lastFlash       time.Time
+	lingerUntil     time.Time
+	lastChange      time.Time
+	seq             int
+	priority        int
+	weight          int
+	namespace       string
+	pulse           time.Duration
+	pulseOff        bool
+	nextPulse       time.Time
+	obj             interface{}
+}
+
+// WithScrollInterval pages through resources that don't fit on the strip,
+// advancing by one page every interval. Zero disables scrolling.
+func WithScrollInterval(interval time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.ScrollInterval = interval
+	}
+}
+
+// WithLEDCount sets how many pixels the strip has, e.g. 4 for a Blinkt zero.
+// A count larger than the driver can address is clamped to it, with a
+// warning.
+func WithLEDCount(count int) Option {
+	return func(o *ControllerObj) {
+		o.ledCount = count
+	}
+}
+
+// WithStableSlots keeps every resource on the same LED for its lifetime, so a
+// deletion leaves a dark gap instead of shifting the following resources.
+func WithStableSlots(stable bool) Option {
+	return func(o *ControllerObj) {
+		o.StableSlots = stable
+	}
+}
+
+// WithGroupByNamespace keeps the resources of each namespace together in a
+// band of LEDs, separated by a dark LED when there is room. When they don't
+// all fit, every namespace gets its share of the strip and its own overflow
+// marker. Pinned and stable slots are ignored while grouping.
+func WithGroupByNamespace(group bool) Option {
+	return func(o *ControllerObj) {
+		o.GroupByNamespace = group
+	}
+}
+
+// WithFlash sets how many times and for how long an LED flashes when its
+// resource changes. A count of zero sets the new color without flashing.
+func WithFlash(count int, duration time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.FlashCount = count
+		o.FlashDuration = duration
+	}
+}
+
+// WithFlashColor flashes added and updated resources in color, to draw the
+// eye, before they settle to their own color. Empty flashes them in their own
+// color.
+func WithFlashColor(color string) Option {
+	return func(o *ControllerObj) {
+		o.FlashColor = color
+	}
+}
+
+// WithDeleteLinger keeps a deleted resource on its LED in color for linger
+// after it flashes, so short-lived resources aren't missed, before freeing
+// the LED. Empty colors it red. Adding the resource back during that time
+// keeps it. It has no effect in aggregate, bar or inverted mode.
+func WithDeleteLinger(linger time.Duration, color string) Option {
+	return func(o *ControllerObj) {
+		o.DeleteLinger = linger
+		o.DeleteColor = color
+	}
+}
+
+// WithMonochrome shows every resource in color, white if empty, at the
+// brightness intensity returns for it, for boards where only brightness
+// carries meaning. Color functions and annotations are not used then.
+func WithMonochrome(color string, intensity BrightnessFunc) Option {
+	return func(o *ControllerObj) {
+		o.MonochromeColor = color
+		o.IntensityFunc = intensity
+	}
+}
+
+// WithSortByActivity shows the most recently added or updated resources
+// first, so on a full strip the ones that change stay visible. Priority
+// annotations still come first.
+func WithSortByActivity(activity bool) Option {
+	return func(o *ControllerObj) {
+		o.SortByActivity = activity
+	}
+}
+
+// WithFlashPatterns flashes resources of the colors in patterns, in any form
+// a ColorFunc may return, with their pattern instead of the FlashCount
+// flashes of FlashDuration.
+func WithFlashPatterns(patterns map[string]FlashPattern) Option {
+	return func(o *ControllerObj) {
+		o.FlashPatterns = patterns
+	}
+}
+
+// WithErrorColor makes the whole strip blink in color while listing or
+// watching any resource fails, until it works again. Failures are logged and
+// counted either way.
+func WithErrorColor(color string) Option {
+	return func(o *ControllerObj) {
+		o.ErrorColor = color
+	}
+}
+
+// WithLessFunc sorts the resources with less before every render instead of
+// showing them in the order the informer delivered them. It has no effect on
+// stable slots, which never move.
+func WithLessFunc(less LessFunc) Option {
+	return func(o *ControllerObj) {
+		o.Less = less
+	}
+}
+
+// WithIdleAnimation plays a slow rainbow sweep while there is nothing to show.
+// It never plays in the aggregate and bar modes, which own the whole strip.
+func WithIdleAnimation(idle bool) Option {
+	return func(o *ControllerObj) {
+		o.IdleAnimation = idle
+	}
+}
+
+// WithHeartbeat reserves the LED at slot and pulses it in color for as long as
+// the controller runs. Resources are shown on the remaining LEDs. A slot of -1
+// disables the heartbeat.
+func WithHeartbeat(slot int, color string) Option {
+	return func(o *ControllerObj) {
+		o.HeartbeatSlot = slot
+		o.HeartbeatColor = color
+	}
+}
+
+// WithCleanupColor sets the color the strip shows once Cleanup is called.
+func WithCleanupColor(color string) Option {
+	return func(o *ControllerObj) {
+		o.CleanupColor = color
+	}
+}
+
+// WithKeyFunc replaces the default namespace/name key, e.g. to group objects
+// by label.
+func WithKeyFunc(keyFunc KeyFunc) Option {
+	return func(o *ControllerObj) {
+		o.KeyFunc = keyFunc
+	}
+}
+
+// WithOverflowColor turns the last LED into a blinking marker whenever there
+// are more resources than LEDs. The marker gets brighter the more resources
+// are hidden.
+func WithOverflowColor(color string) Option {
+	return func(o *ControllerObj) {
+		o.OverflowColor = color
+	}
+}
+
+// WithRefreshInterval batches updates and renders the strip at most once per
+// interval instead of after every event. Zero renders every event.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.RefreshInterval = interval
+	}
+}
+
+// WithLogger sends the controller's messages to logger instead of the
+// standard logger at info level.
+func WithLogger(logger Logger) Option {
+	return func(o *ControllerObj) {
+		o.logger = logger
+	}
+}
+
+// WithLogOutput writes the controller's messages to w at info level, in the
+// same format as the standard logger. Use WithLogger and NewLoggerTo for
+// another level.
+func WithLogOutput(w io.Writer) Option {
+	return func(o *ControllerObj) {
+		o.logger = NewLoggerTo(log.New(w, "", log.LstdFlags), LevelInfo)
+	}
+}
+
+// WithTransition fades an updated LED from its previous color to the new one
+// over duration instead of flashing it. Zero disables the fade.
+func WithTransition(duration time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.TransitionDuration = duration
+	}
+}
+
+// WithSelector only gives LEDs to objects whose labels match selector, without
+// narrowing what is watched. An object that stops matching loses its LED.
+func WithSelector(selector labels.Selector) Option {
+	return func(o *ControllerObj) {
+		o.Selector = selector
+	}
+}
+
+// WithAggregateMode shows how many resources share each color instead of one
+// LED per resource: every color gets a number of LEDs proportional to its
+// share of the resources.
+func WithAggregateMode(aggregate bool) Option {
+	return func(o *ControllerObj) {
+		o.AggregateMode = aggregate
+	}
+}
+
+// WithBlendOverflow makes aggregate mode share the LEDs out between every
+// resource when there are more colors than LEDs, each LED showing the
+// average of the colors it stands for, instead of leaving the rarest colors
+// out.
+func WithBlendOverflow(blend bool) Option {
+	return func(o *ControllerObj) {
+		o.BlendOverflow = blend
+	}
+}
+
+// WithInvertSlots shows free capacity instead of resources: the LEDs of
+// resources are off and the free ones are lit in color.
+func WithInvertSlots(color string) Option {
+	return func(o *ControllerObj) {
+		o.InvertSlots = true
+		o.InvertColor = color
+	}
+}
+
+// WithBar turns the strip into a bar graph of value, which must return a
+// fraction in [0, 1]: the first round(value*LEDs) LEDs are lit in color and
+// the rest are off. Resources are still watched but not shown. value is
+// called on every render, and on every tick when combined with
+// WithRefreshInterval.
+func WithBar(value func() float64, color string) Option {
+	return func(o *ControllerObj) {
+		o.BarFunc = value
+		o.BarColor = color
+	}
+}
+
+// WithStateFile saves what the strip shows to path, and restores it from
+// there when the controller starts so the LEDs don't go dark while the
+// informers catch up.
+func WithStateFile(path string) Option {
+	return func(o *ControllerObj) {
+		o.StateFile = path
+	}
+}
+
+// WithConfigFile reloads the brightness, flash, refresh interval and cleanup
+// color settings from a JSON file at path whenever the process gets a SIGHUP.
+// An invalid file is logged and leaves the settings as they were.
+func WithConfigFile(path string) Option {
+	return func(o *ControllerObj) {
+		o.ConfigFile = path
+	}
+}
+
+// WithPauseSignal freezes the display on SIGUSR1 until the next SIGUSR1.
+// Watches keep running while paused.
+func WithPauseSignal(pause bool) Option {
+	return func(o *ControllerObj) {
+		o.PauseOnSignal = pause
+	}
+}
+
+// WithPauseBlank turns the LEDs off while paused instead of freezing them.
+func WithPauseBlank(blank bool) Option {
+	return func(o *ControllerObj) {
+		o.PauseBlank = blank
+	}
+}
+
+// WithAlertColors keeps blinking the LEDs of resources whose color is one of
+// colors, once a second, until they change to another color.
+func WithAlertColors(colors ...string) Option {
+	return func(o *ControllerObj) {
+		o.AlertColors = colors
+	}
+}
+
+// WithNightDimming scales every LED's brightness by brightness between the
+// start and end times of day, given as HH:MM. The night may span midnight.
+func WithNightDimming(brightness float64, start, end string) Option {
+	return func(o *ControllerObj) {
+		o.NightBrightness = brightness
+		o.NightStart = start
+		o.NightEnd = end
+	}
+}
+
+// WithLocation sets the time zone night dimming hours are in. It defaults to
+// the local time zone.
+func WithLocation(loc *time.Location) Option {
+	return func(o *ControllerObj) {
+		o.Location = loc
+	}
+}
+
+// WithHealthThreshold makes HealthHandler fail when the strip hasn't been
+// shown for longer than threshold. Only use it when something redraws the
+// strip regularly, such as the heartbeat or the idle animation.
+func WithHealthThreshold(threshold time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.HealthThreshold = threshold
+	}
+}
+
+// WithRepaintInterval redraws the whole strip every interval even when
+// nothing changed, so an LED corrupted by a glitch on the wire doesn't stay
+// wrong until its resource next changes.
+func WithRepaintInterval(interval time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.RepaintInterval = interval
+	}
+}
+
+// WithOverflowFunc calls f with the keys of the resources that don't fit on
+// the strip, or an empty list once they all fit again, whenever they change.
+// f runs on its own goroutine without any lock held; if it falls behind it
+// only gets the latest list.
+func WithOverflowFunc(f func(hidden []string)) Option {
+	return func(o *ControllerObj) {
+		o.OnOverflow = f
+	}
+}
+
+// WithColorChangeFunc calls f whenever an update changes the color of a
+// resource, for instance to sound a buzzer when one turns red. f runs on its
+// own goroutine without any lock held, one change at a time; changes are
+// dropped if it falls too far behind.
+func WithColorChangeFunc(f func(key, oldColor, newColor string)) Option {
+	return func(o *ControllerObj) {
+		o.OnColorChange = f
+	}
+}
+
+// WithStartupAnimation sweeps across every LED once when the controller
+// starts, before anything else is drawn, to show that none of them is dead.
+func WithStartupAnimation(sweep bool) Option {
+	return func(o *ControllerObj) {
+		o.StartupAnimation = sweep
+	}
+}
+
+// WithWeightFunc lets resources take several consecutive LEDs each, as many
+// as weight returns for them.
+func WithWeightFunc(weight WeightFunc) Option {
+	return func(o *ControllerObj) {
+		o.WeightFunc = weight
+	}
+}
+
+// WithClock replaces the clock behind every timer and animation of the
+// controller, flashes included.
+func WithClock(clock Clock) Option {
+	return func(o *ControllerObj) {
+		o.clock = clock
+	}
+}
+
+// WithBlankOnCleanup turns every LED off on Cleanup instead of lighting
+// them in the cleanup color, which it takes precedence over.
+func WithBlankOnCleanup(blank bool) Option {
+	return func(o *ControllerObj) {
+		o.BlankOnCleanup = blank
+	}
+}
+
+// WithMinFlashInterval stops a resource from flashing more than once every
+// interval: a change that comes sooner just sets the new color, so a
+// flapping resource doesn't strobe.
+func WithMinFlashInterval(interval time.Duration) Option {
+	return func(o *ControllerObj) {
+		o.MinFlashInterval = interval
+	}
+}
+
+// WithDryRun replaces the driver with a DryRunBlinkt that logs what the
+// strip would show through the controller's logger.
+func WithDryRun(dryRun bool) Option {
+	return func(o *ControllerObj) {
+		o.DryRun = dryRun
+	}
+}
+
+// WithMaxResources caps how many resources are tracked at max, evicting
+// others as policy says once there are more. Zero, the default, tracks every
+// resource. Evicted resources are reported to the overflow callback.
+func WithMaxResources(max int, policy EvictionPolicy) Option {
+	return func(o *ControllerObj) {
+		o.MaxResources = max
+		o.Eviction = policy
+	}
+}
+
+// WithResyncJitter stretches the resync period of every watch, and the
+// refresh and repaint intervals, by a random fraction of up to jitter, so
+// controllers started together don't all hit the API server and redraw at
+// the same time.
+func WithResyncJitter(jitter float64) Option {
+	return func(o *ControllerObj) {
+		o.ResyncJitter = jitter
+	}
+}
+
+// WithTick re-runs the ColorFunc of every watched object for which tick
+// returns true, or of all of them if tick is nil, once per interval, for
+// colors that change with time rather than with the object. Informer
+// resyncs already re-run it for every object, so the interval should be
+// well below the resync period: a resync as short as the interval only
+// doubles the work.
+func WithTick(interval time.Duration, tick TickFunc) Option {
+	return func(o *ControllerObj) {
+		o.TickInterval = interval
+		o.TickFunc = tick
+	}
+}
+
+// WithSkipUnchanged remembers what every LED was last set to and only
+// writes to the strip what changed since, to cut SPI traffic on a steady
+// board.
+func WithSkipUnchanged(skip bool) Option {
+	return func(o *ControllerObj) {
+		o.SkipUnchanged = skip
+	}
+}
+
+// WithPulseRateFunc makes the LED of every resource for which rate returns
+// a non-zero duration pulse, on then off for that long each.
+func WithPulseRateFunc(rate PulseRateFunc) Option {
+	return func(o *ControllerObj) {
+		o.PulseRateFunc = rate
+	}
+}
+
+// WithIndexMapper sends what the controller draws on LED i to the physical
+// LED mapper(i), for boards mounted the other way round or wired as a ring.
+// mapper must be a bijection over the LEDs of the strip.
+func WithIndexMapper(mapper IndexMapper) Option {
+	return func(o *ControllerObj) {
+		o.IndexMapper = mapper
+	}
+}
+
+// WithReverse numbers the LEDs from the other end of the strip, for boards
+// mounted upside down. Unlike ReverseIndexMapper, it follows the LED count
+// the controller settles on, and it replaces any IndexMapper.
+func WithReverse(reverse bool) Option {
+	return func(o *ControllerObj) {
+		o.Reverse = reverse
+	}
+}
+
+// WithTracerProvider records an OpenTelemetry span for every added, updated
+// and deleted resource and for every render. Without one nothing is traced.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *ControllerObj) {
+		o.TracerProvider = provider
+	}
+}
+
+// WithGamma corrects brightness and color channels with the given gamma before
+// they reach the strip. A gamma of 1 leaves them untouched.
+func WithGamma(gamma float64) Option {
+	return func(o *ControllerObj) {
+		o.Gamma = gamma
+	}
+}
+
+// NewController drives the Blinkt on the GPIO header, or draws the strip in the
+// terminal when BLINKT_SIMULATOR is set. It panics if the strip can't be
+// initialized.
+func NewController(brightness float64, opts ...Option) Controller {
+	c, err := NewControllerE(brightness, opts...)
+	if err != nil {
+		log.Panicln(err.Error())
+	}
+	return c
+}
+
+// NewControllerE is like NewController but returns an error instead of
+// panicking, so callers can fall back to another driver or exit cleanly.
+func NewControllerE(brightness float64, opts ...Option) (Controller, error) {
+	var probe ControllerObj
+	for _, opt := range opts {
+		opt(&probe)
+	}
+	var b BlinktDriver
+	switch {
+	case probe.DryRun:
+		// newController installs the dry run driver once the logger is known.
+	case os.Getenv("BLINKT_SIMULATOR") != "":
+		b = NewTerminalBlinkt(os.Stdout, maxLEDCount)
+	default:
+		strip, err := newBlinkt(brightness)
+		if err != nil {
+			return nil, err
+		}
+		b = NewBlinktDriver(strip)
+	}
+	o, err := newController(brightness, b, opts)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func newBlinkt(brightness float64) (strip *blinkt.Blinkt, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not initialize the Blinkt: %v", r)
+		}
+	}()
+	b := blinkt.NewBlinkt(blinkt.Blue, clampBrightness(brightness))
+	return &b, nil
+}
+
+func NewControllerWithBlinkt(brightness float64, b BlinktDriver, opts ...Option) Controller {
+	o, err := newController(brightness, b, opts)
+	if err != nil {
+		log.Panicln(err.Error())
+	}
+	return o
+}
+
+func newController(brightness float64, b BlinktDriver, opts []Option) (*ControllerObj, error) {
+	o := &ControllerObj{
+		brightness:          brightness,
+		ledCount:            maxLEDCount,
+		FlashCount:          2,
+		FlashDuration:       50 * time.Millisecond,
+		HeartbeatSlot:       -1,
+		CleanupColor:        blinkt.Red,
+		Gamma:               1,
+		Location:            time.Local,
+		clock:               realClock{},
+		KeyFunc:             cache.DeletionHandlingMetaNamespaceKeyFunc,
+		logger:              NewLogger(LevelInfo),
+		subscribers:         map[chan boardState]struct{}{},
+		snapshotSubscribers: map[chan []ResourceView]struct{}{},
+		events:              make(chan Event, eventBuffer),
+		colorChanges:        make(chan Event, eventBuffer),
+		resourceList:        []resource{},
+		resourceLock:        &sync.Mutex{},
+		blinkt:              b,
+		stopCh:              make(chan struct{}),
+		stale:               true,
+		reloaded:            make(chan struct{}, 1),
+		hiddenCh:            make(chan []string, 1),
+		evicted:             map[resourceID]bool{},
+		legend:              map[string]string{},
+		metrics:             newMetrics(),
+	}
+	pixels := maxLEDCount
+	if p, ok := b.(pixelCounter); ok {
+		pixels = p.Pixels()
+		o.ledCount = pixels
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.DryRun {
+		o.blinkt = NewDryRunBlinkt(o.logger, pixels)
+	}
+	if clamped := clampBrightness(brightness); clamped != brightness {
+		o.logger.Warn("Brightness out of range, clamping", "brightness", brightness, "clamped", clamped)
+		o.brightness = clamped
+	}
+	if o.ledCount <= 0 {
+		return nil, fmt.Errorf("invalid LED count %d: the strip can address 1 to %d LEDs", o.ledCount, pixels)
+	}
+	if o.ledCount > pixels {
+		o.logger.Warn("LED count larger than the strip, only using the LEDs it has", "ledCount", o.ledCount, "pixels", pixels)
+		o.ledCount = pixels
+	}
+	if o.HeartbeatSlot < -1 || o.HeartbeatSlot >= o.ledCount {
+		return nil, fmt.Errorf("invalid heartbeat slot %d: must be -1 or between 0 and %d", o.HeartbeatSlot, o.ledCount-1)
+	}
+	if o.slots() < 1 {
+		return nil, fmt.Errorf("invalid heartbeat slot %d: it leaves no LED for resources", o.HeartbeatSlot)
+	}
+	if o.OverflowColor != "" && o.slots() < 2 {
+		return nil, fmt.Errorf("invalid overflow color %s: the marker needs an LED besides the one left for resources", o.OverflowColor)
+	}
+	o.alerts = map[string]bool{}
+	for _, c := range o.AlertColors {
+		color, err := parseColor(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert color: %v", err)
+		}
+		o.alerts[color] = true
+	}
+	if o.FlashColor != "" {
+		color, err := parseColor(o.FlashColor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flash color: %v", err)
+		}
+		o.FlashColor = color
+	}
+	if len(o.FlashPatterns) > 0 {
+		patterns := make(map[string]FlashPattern, len(o.FlashPatterns))
+		for c, pattern := range o.FlashPatterns {
+			color, err := parseColor(c)
+			if err != nil {
+				return nil, fmt.Errorf("invalid flash pattern color: %v", err)
+			}
+			if err := pattern.check(); err != nil {
+				return nil, err
+			}
+			patterns[color] = pattern
+		}
+		o.FlashPatterns = patterns
+	}
+	if o.ErrorColor != "" {
+		color, err := parseColor(o.ErrorColor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error color: %v", err)
+		}
+		o.ErrorColor = color
+	}
+	if o.DeleteLinger > 0 {
+		if o.DeleteColor == "" {
+			o.DeleteColor = blinkt.Red
+		}
+		color, err := parseColor(o.DeleteColor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delete color: %v", err)
+		}
+		o.DeleteColor = color
+	}
+	if o.IntensityFunc != nil {
+		if o.MonochromeColor == "" {
+			o.MonochromeColor = "FFFFFF"
+		}
+		color, err := parseColor(o.MonochromeColor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid monochrome color: %v", err)
+		}
+		o.MonochromeColor = color
+	}
+	if o.ResyncJitter < 0 {
+		return nil, fmt.Errorf("invalid resync jitter %v: must not be negative", o.ResyncJitter)
+	}
+	o.jitter = o.jitterFactor()
+	if o.Reverse {
+		o.IndexMapper = ReverseIndexMapper(o.ledCount)
+	}
+	if o.TracerProvider == nil {
+		o.TracerProvider = trace.NewNoopTracerProvider()
+	}
+	o.tracer = o.TracerProvider.Tracer(tracerName)
+	if o.IndexMapper != nil {
+		if err := checkMapper(o.IndexMapper, o.ledCount); err != nil {
+			return nil, err
+		}
+		o.blinkt = mappedBlinkt{o.blinkt, o.IndexMapper}
+	}
+	if o.Gamma <= 0 {
+		return nil, fmt.Errorf("invalid gamma %v: must be positive", o.Gamma)
+	}
+	if o.Gamma != 1 {
+		o.blinkt = gammaBlinkt{o.blinkt, o.Gamma}
+	}
+	if o.NightStart != "" || o.NightEnd != "" {
+		var err error
+		if o.nightStart, err = parseClock(o.NightStart); err != nil {
+			return nil, err
+		}
+		if o.nightEnd, err = parseClock(o.NightEnd); err != nil {
+			return nil, err
+		}
+		if o.NightBrightness < 0 || o.NightBrightness > 1 {
+			return nil, fmt.Errorf("invalid night brightness %v: must be between 0 and 1", o.NightBrightness)
+		}
+		if o.Location == nil {
+			o.Location = time.Local
+		}
+		o.dimmer = &dimBlinkt{o.blinkt, 1}
+		if o.night(o.clock.Now()) {
+			o.dimmer.factor = o.NightBrightness
+		}
+		o.blinkt = o.dimmer
+	}
+	o.driver = &lockedBlinkt{b: o.blinkt, clock: o.clock}
+	o.blinkt = o.driver
+	if o.StateFile != "" {
+		o.restoreState()
+	}
+	return o, nil
+}
+
+func (o *ControllerObj) Watch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc, stopCh <-chan struct{}) {
+	o.WatchE(listWatch, objType, resyncPeriod, func(obj interface{}) (string, error) {
+		return colorFunc(obj), nil
+	}, stopCh)
+}
+
+func (o *ControllerObj) WatchE(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFuncE, stopCh <-chan struct{}) {
+	o.watch(listWatch, objType, resyncPeriod, colorFunc, nil, stopCh)
+}
+
+// WatchWithBrightness is like Watch but renders each resource at the
+// brightness returned by brightnessFunc.
+func (o *ControllerObj) WatchWithBrightness(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc, brightnessFunc BrightnessFunc, stopCh <-chan struct{}) {
+	o.watch(listWatch, objType, resyncPeriod, func(obj interface{}) (string, error) {
+		return colorFunc(obj), nil
+	}, brightnessFunc, stopCh)
+}
+
+func (o *ControllerObj) watch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFuncE, brightnessFunc BrightnessFunc, stopCh <-chan struct{}) {
+	o.run(stopCh, o.newInformer(listWatch, objType, resyncPeriod, colorFunc, brightnessFunc))
+}
+
+// AddWatch registers a watch to be started by Run. Every watch has its own
+// color function and owns the resources it adds, so objects with the same key
+// from different watches get separate LEDs.
+func (o *ControllerObj) AddWatch(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc) {
+	informer := o.newInformer(listWatch, objType, resyncPeriod, func(obj interface{}) (string, error) {
+		return colorFunc(obj), nil
+	}, nil)
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.informers = append(o.informers, informer)
+}
+
+// Run starts every watch registered with AddWatch and blocks until stopCh is
+// closed or Stop is called.
+func (o *ControllerObj) Run(stopCh <-chan struct{}) {
+	o.resourceLock.Lock()
+	informers := o.informers
+	o.informers = nil
+	o.resourceLock.Unlock()
+	o.run(stopCh, informers...)
+}
+
+func (o *ControllerObj) newInformer(listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFuncE, brightnessFunc BrightnessFunc) cache.Controller {
+	source, handler := o.newHandler(colorFunc, brightnessFunc)
+	_, controller := newInformer(o.guard(source, listWatch), objType, o.jittered(resyncPeriod), handler)
+	return controller
+}
+
+// newHandler sets up a new source of resources and returns it with the
+// event handlers that feed it.
+func (o *ControllerObj) newHandler(colorFunc ColorFuncE, brightnessFunc BrightnessFunc) (int, cache.ResourceEventHandlerFuncs) {
+	o.resourceLock.Lock()
+	source := o.sources
+	o.sources++
+	o.resourceLock.Unlock()
+	// evaluate must be called with resourceLock held.
+	evaluate := func(obj interface{}) {
+		key, err := o.keyFunc(obj)
+		if err != nil {
+			o.logger.Warn("Skipping object without a key", "error", err)
+			return
+		}
+		if !o.selected(obj) {
+			o.removeResource(source, key)
+			return
+		}
+		color, ok := o.MonochromeColor, o.IntensityFunc != nil
+		if !ok {
+			color, ok = o.annotationColor(obj)
+		}
+		if !ok {
+			colorFunc := colorFunc
+			if o.colorFunc != nil {
+				colorFunc = o.colorFunc
+			}
+			color, err = safeColor(colorFunc, obj)
+			if err != nil {
+				o.logger.Warn("Skipping resource", "key", key, "error", err)
+				return
+			}
+			if color, err = parseColor(color); err != nil {
+				o.logger.Warn("Turning off resource with an invalid color", "key", key, "error", err)
+				color = blinkt.Off
+			}
+		}
+		brightness := float64(defaultBrightness)
+		weight := 1
+		var pulse time.Duration
+		err = safely("intensity, brightness, weight or pulse rate function", func() {
+			switch {
+			case o.IntensityFunc != nil:
+				brightness = clampBrightness(o.IntensityFunc(obj))
+			case brightnessFunc != nil:
+				brightness = clampBrightness(brightnessFunc(obj))
+			}
+			if o.WeightFunc != nil {
+				weight = o.WeightFunc(obj)
+			}
+			if o.PulseRateFunc != nil {
+				pulse = o.PulseRateFunc(obj)
+			}
+		})
+		if err != nil {
+			o.logger.Warn("Skipping resource", "key", key, "error", err)
+			return
+		}
+		o.setResource(resource{
+			source:     source,
+			key:        key,
+			color:      color,
+			brightness: brightness,
+			pin:        o.annotationSlot(obj),
+			priority:   o.annotationPriority(obj),
+			weight:     weight,
+			pulse:      pulse,
+			obj:        obj,
+		})
+	}
+	set := func(obj interface{}) {
+		obj = unwrap(obj)
+		o.resourceLock.Lock()
+		defer o.resourceLock.Unlock()
+		evaluate(obj)
+	}
+	o.resourceLock.Lock()
+	if o.evaluators == nil {
+		o.evaluators = make(map[int]func(obj interface{}))
+	}
+	o.evaluators[source] = evaluate
+	o.resourceLock.Unlock()
+	return source, cache.ResourceEventHandlerFuncs{
+		AddFunc: set,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			set(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			o.resourceLock.Lock()
+			defer o.resourceLock.Unlock()
+			key, err := o.keyFunc(obj)
+			if err != nil {
+				o.logger.Warn("Skipping object without a key", "error", err)
+				return
+			}
+			o.removeResource(source, key)
 		},
-	)
-	sigs := make(chan os.Signal)
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	stopCh := make(chan struct{})
+	}
+}
+
+func (o *ControllerObj) run(stopCh <-chan struct{}, informers ...cache.Controller) {
+	o.resourceLock.Lock()
+	o.syncing++
+	o.resourceLock.Unlock()
+	o.runSyncing(stopCh, informers...)
+}
+
+// runSyncing is run for informers already counted in syncing, and takes
+// them off once they synced.
+func (o *ControllerObj) runSyncing(stopCh <-chan struct{}, informers ...cache.Controller) {
+	done := make(chan struct{})
 	go func() {
-		<-sigs
-		log.Println("Stopping the Blinkt controller...")
-		close(stopCh)
+		select {
+		case <-stopCh:
+		case <-o.stopCh:
+		}
+		close(done)
 	}()
-	log.Println("Starting the Blinkt controller...")
-	controller.Run(stopCh)
+	o.running.Add(1)
+	defer o.running.Done()
+	// The animations and timers outlive any single watch: they run until
+	// Stop, however many times run is called.
+	o.backgroundOnce.Do(func() {
+		if o.StartupAnimation {
+			o.sweep()
+		}
+		if o.ScrollInterval > 0 {
+			o.background(o.scroll, o.stopCh)
+		}
+		if o.IdleAnimation {
+			o.background(o.idle, o.stopCh)
+		}
+		if o.HeartbeatSlot >= 0 {
+			o.background(o.heartbeat, o.stopCh)
+		}
+		if o.OverflowColor != "" {
+			o.background(o.blinkOverflow, o.stopCh)
+		}
+		if o.RefreshInterval > 0 || o.ConfigFile != "" {
+			o.background(o.refresh, o.stopCh)
+		}
+		if o.ConfigFile != "" {
+			o.background(o.reload, o.stopCh)
+		}
+		if o.PauseOnSignal {
+			o.background(o.pauseOnSignal, o.stopCh)
+		}
+		if len(o.alerts) > 0 {
+			o.background(o.blinkAlerts, o.stopCh)
+		}
+		if o.dimmer != nil {
+			o.background(o.dimAtNight, o.stopCh)
+		}
+		if o.RepaintInterval > 0 {
+			o.background(o.repaint, o.stopCh)
+		}
+		if o.OnOverflow != nil {
+			o.background(o.notifyOverflow, o.stopCh)
+		}
+		if o.OnColorChange != nil {
+			o.background(o.notifyColorChange, o.stopCh)
+		}
+		if o.PulseRateFunc != nil {
+			o.background(o.pulse, o.stopCh)
+		}
+		if o.TickInterval > 0 {
+			o.background(o.tick, o.stopCh)
+		}
+		if o.lingers() {
+			o.background(o.expireLingering, o.stopCh)
+		}
+		if o.ErrorColor != "" {
+			o.background(o.blinkWatchErrors, o.stopCh)
+		}
+	})
+	o.logger.Info("Starting the Blinkt controller")
+	var wg sync.WaitGroup
+	synced := make([]cache.InformerSynced, len(informers))
+	for i, informer := range informers {
+		synced[i] = informer.HasSynced
+		wg.Add(1)
+		go func(informer cache.Controller) {
+			defer wg.Done()
+			informer.Run(done)
+		}(informer)
+	}
+	ok := cache.WaitForCacheSync(done, synced...)
+	o.resourceLock.Lock()
+	o.syncing--
+	if ok {
+		o.logger.Info("Informer caches synced", "resources", len(o.resourceList))
+		o.synced = true
+	} else {
+		o.logger.Warn("Stopped before the informer caches synced")
+	}
+	if o.syncing == 0 {
+		o.renderSynced()
+	}
+	o.resourceLock.Unlock()
+	wg.Wait()
+}
+
+// renderSynced draws the initial list in one go. Its resources appear
+// without flashing, since they weren't added while the controller watched.
+func (o *ControllerObj) renderSynced() {
+	for i := range o.resourceList {
+		if o.resourceList[i].state != deleted {
+			o.resourceList[i].state = unchanged
+			o.resourceList[i].shown = true
+		}
+	}
+	o.dirty = false
+	o.stale = true
+	o.updateBlinkt()
+}
+
+// WatchContext is like Watch but stops when ctx is cancelled.
+func (o *ControllerObj) WatchContext(ctx context.Context, listWatch *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration, colorFunc ColorFunc) {
+	o.Watch(listWatch, objType, resyncPeriod, colorFunc, ctx.Done())
+}
+
+// HandleSignals returns a channel that is closed on SIGINT or SIGTERM. The
+// handler is only registered once, so every call returns the same channel.
+func (o *ControllerObj) HandleSignals() <-chan struct{} {
+	o.signalOnce.Do(func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		o.signalStopCh = make(chan struct{})
+		go func() {
+			<-sigs
+			o.logger.Info("Stopping the Blinkt controller")
+			close(o.signalStopCh)
+		}()
+	})
+	return o.signalStopCh
+}
+
+// Stop stops every running watch and waits for the informers to return. It
+// is safe to call more than once.
+func (o *ControllerObj) Stop() {
+	o.stopOnce.Do(func() {
+		close(o.stopCh)
+	})
+	o.running.Wait()
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	if !o.eventsClosed {
+		close(o.events)
+		o.eventsClosed = true
+	}
+	if o.saveTimer != nil {
+		o.saveState()
+	}
 }
 
 func (o *ControllerObj) Cleanup() {
-	o.blinkt.Cleanup(blinkt.Red, o.brightness)
+	o.Stop()
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	if o.BlankOnCleanup {
+		for i := 0; i < o.ledCount; i++ {
+			o.set(i, blinkt.Off, 0)
+		}
+		o.show()
+		return
+	}
+	if err := o.blinkt.Cleanup(o.CleanupColor, o.brightness); err != nil {
+		o.driverError("cleanup", err)
+	}
+	o.forget()
+}
+
+func (o *ControllerObj) setResource(n resource) {
+	if o.evicted[resourceID{n.source, n.key}] {
+		return
+	}
+	r := o.getResource(n.source, n.key)
+	if r == nil {
+		o.addResource(n)
+		return
+	}
+	o.stale = true
+	r.obj = n.obj
+	reorder := n.priority != r.priority || n.weight != r.weight || n.pulse != r.pulse
+	r.priority = n.priority
+	r.weight = n.weight
+	r.pulse = n.pulse
+	if r.state != deleted && n.color == r.color && n.brightness == r.brightness && n.pin == r.pin {
+		if reorder {
+			o.render()
+		}
+		return
+	}
+	span := o.startEvent(updated, r.key, n.color)
+	o.logger.Info("Updating resource", "key", r.key, "color", n.color, "state", stateNames[updated])
+	o.metrics.events.WithLabelValues("updated").Inc()
+	o.emit(Event{EventUpdated, r.key, r.color, n.color})
+	o.colorChanged(r.key, r.color, n.color)
+	r.prevColor = r.color
+	r.lingerUntil = time.Time{}
+	r.lastChange = o.clock.Now()
+	r.color = n.color
+	r.brightness = n.brightness
+	if n.pin != r.pin {
+		o.checkPin(n)
+		r.pin = n.pin
+		o.relayout()
+	}
+	r.state = updated
+	span.End()
+	o.render()
+}
+
+func (o *ControllerObj) addResource(r resource) {
+	span := o.startEvent(added, r.key, r.color)
+	o.stale = true
+	r.state = added
+	r.slot = o.freeSlot()
+	r.seq = o.added
+	r.lastChange = o.clock.Now()
+	o.added++
+	r.namespace, _, _ = cache.SplitMetaNamespaceKey(r.key)
+	if !o.admits(&r) {
+		span.End()
+		o.reportHidden()
+		return
+	}
+	o.logger.Info("Adding resource", "key", r.key, "color", r.color, "state", stateNames[added])
+	o.metrics.events.WithLabelValues("added").Inc()
+	o.checkPin(r)
+	o.resourceList = append(o.resourceList, r)
+	o.relayout()
+	o.emit(Event{EventAdded, r.key, "", r.color})
+	span.End()
+	o.evict()
+	o.render()
+}
+
+func (o *ControllerObj) deleteResource(r *resource) {
+	if r.state == deleted {
+		return
+	}
+	span := o.startEvent(deleted, r.key, r.color)
+	o.stale = true
+	o.logger.Info("Deleting resource", "key", r.key, "color", r.color, "state", stateNames[deleted])
+	o.metrics.events.WithLabelValues("deleted").Inc()
+	o.emit(Event{EventDeleted, r.key, r.color, ""})
+	r.state = deleted
+	span.End()
+	o.render()
+}
+
+func (o *ControllerObj) selected(obj interface{}) bool {
+	if o.Selector == nil {
+		return true
+	}
+	m, ok := unwrap(obj).(metav1.Object)
+	return ok && o.Selector.Matches(labels.Set(m.GetLabels()))
 }
 
-func (o *ControllerObj) getResource(key string) *resource {
+func (o *ControllerObj) getResource(source int, key string) *resource {
 	for i, r := range o.resourceList {
-		if r.key == key {
+		if r.source == source && r.key == key {
 			return &o.resourceList[i]
 		}
 	}
 	return nil
 }
 
-func (o *ControllerObj) updateBlinkt() {
-	i := 0
-	for ; i < len(o.resourceList); i++ {
-		r := &o.resourceList[i]
-		switch r.state {
-		case added:
-			fallthrough
-		case updated:
-			if i < 8 {
-				o.blinkt.Flash(i, r.color, o.brightness, 2, 50*time.Millisecond)
-				o.blinkt.Set(i, r.color, o.brightness)
+func (o *ControllerObj) background(f func(stopCh <-chan struct{}), stopCh <-chan struct{}) {
+	o.running.Add(1)
+	go func() {
+		defer o.running.Done()
+		f(stopCh)
+	}()
+}
+
+func (o *ControllerObj) idle(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	hue := 0.0
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if len(o.resourceList) == 0 && o.perResource() && !o.paused {
+				for i := 0; i < o.slots(); i++ {
+					o.set(o.led(i), hsvToColor(hue+360*float64(i)/float64(o.slots()), 1, 1), o.brightness)
+				}
+				o.show()
+				hue = math.Mod(hue+3, 360)
 			}
-			r.state = unchanged
-		case deleted:
-			if i < 8 {
-				o.blinkt.Flash(i, r.color, o.brightness, 2, 50*time.Millisecond)
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// sweep lights the LEDs one after the other in rainbow colors, then turns
+// them all off.
+func (o *ControllerObj) sweep() {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	for i := 0; i < o.ledCount; i++ {
+		o.set(i, hsvToColor(360*float64(i)/float64(o.ledCount), 1, 1), o.brightness)
+		o.show()
+		o.clock.Sleep(60 * time.Millisecond)
+	}
+	for i := 0; i < o.ledCount; i++ {
+		o.set(i, blinkt.Off, 0)
+	}
+	o.show()
+}
+
+// heartbeat pulses the reserved LED with a period of two seconds.
+func (o *ControllerObj) heartbeat(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	start := o.clock.Now()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.Chan():
+			level := (1 - math.Cos(math.Pi*now.Sub(start).Seconds())) / 2
+			o.resourceLock.Lock()
+			if !o.paused {
+				o.set(o.HeartbeatSlot, o.HeartbeatColor, o.brightness*level)
+				o.show()
 			}
-			o.resourceList = append(o.resourceList[:i], o.resourceList[i+1:]...)
-			i--
-		case unchanged:
-			if i < 8 {
-				o.blinkt.Set(i, r.color, o.brightness)
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// render draws the strip now, or on the next refresh tick when updates are
+// batched.
+func (o *ControllerObj) render() {
+	if o.syncing > 0 {
+		return
+	}
+	if o.RefreshInterval > 0 {
+		o.dirty = true
+		return
+	}
+	o.updateBlinkt()
+}
+
+func (o *ControllerObj) refresh(stopCh <-chan struct{}) {
+	for {
+		o.resourceLock.Lock()
+		interval := o.RefreshInterval
+		o.resourceLock.Unlock()
+		if !o.refreshEvery(o.jittered(interval), stopCh) {
+			return
+		}
+	}
+}
+
+// refreshEvery flushes pending renders every interval, or never if it is
+// zero. It returns false once stopCh is closed and true when the config is
+// reloaded, so the new interval can take over.
+func (o *ControllerObj) refreshEvery(interval time.Duration, stopCh <-chan struct{}) bool {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := o.clock.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.Chan()
+	}
+	for {
+		select {
+		case <-stopCh:
+			return false
+		case <-o.reloaded:
+			return true
+		case <-tick:
+			o.resourceLock.Lock()
+			if o.dirty || o.BarFunc != nil {
+				o.dirty = false
+				o.updateBlinkt()
 			}
+			o.resourceLock.Unlock()
 		}
 	}
-	for ; i < 8; i++ {
-		o.blinkt.Set(i, blinkt.Off, 0)
+}
+
+func (o *ControllerObj) repaint(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(o.jittered(o.RepaintInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if o.syncing == 0 {
+				o.dirty = false
+				o.updateBlinkt()
+			}
+			o.resourceLock.Unlock()
+		}
 	}
-	o.blinkt.Show()
 }
 
-func keyFunc(obj interface{}) string {
-	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+func (o *ControllerObj) scroll(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(o.ScrollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if o.span() > o.slots() || o.scrollOffset > 0 {
+				o.scrollOffset += o.capacity()
+				if o.scrollOffset >= o.span() {
+					o.scrollOffset = 0
+				}
+				o.stale = true
+				o.updateBlinkt()
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// pendingFlash is the flash of a pruned resource, played once the render
+// span has ended.
+type pendingFlash struct {
+	led        int
+	color      string
+	brightness float64
+	pattern    FlashPattern
+}
+
+// updateBlinkt draws the strip and returns whether Show failed. Only the
+// first render after resources were added, changed or deleted goes through
+// all of them, to prune, sort and lay them out again. Other renders, like
+// repaints or brightness changes, only visit the resources on the strip.
+//
+// The render span covers that layout pass and ends before the driver is
+// called: flashes, fades and Show are left out of it.
+func (o *ControllerObj) updateBlinkt() error {
+	if o.paused {
+		return nil
+	}
+	_, span := o.tracer.Start(context.Background(), "blinkt.render")
+	var flashes []pendingFlash
+	if o.stale {
+		flashes = o.prune()
+		if o.Less != nil || o.prioritized() || o.GroupByNamespace || o.SortByActivity {
+			o.sortResources()
+		}
+		o.relayout()
+		o.reportOverflow()
+	}
+	span.SetAttributes(attribute.Int("blinkt.resources", len(o.resourceList)))
+	span.End()
+	for _, f := range flashes {
+		o.flash(f.led, f.color, f.brightness, f.pattern)
+	}
+	lit := make([]bool, o.slots())
+	switch {
+	case o.BarFunc != nil:
+		o.renderBar(lit)
+	case o.AggregateMode:
+		o.renderAggregate(lit)
+	case o.InvertSlots:
+		o.renderInverted(lit)
+	default:
+		o.renderResources(lit)
+	}
+	for _, m := range o.overflowMarkers() {
+		lit[m.slot] = true
+	}
+	o.renderOverflow()
+	free, freeBrightness := blinkt.Off, 0.0
+	if o.InvertSlots && o.perResource() {
+		free, freeBrightness = o.InvertColor, o.brightness
+	}
+	active := 0
+	for slot := range lit {
+		if lit[slot] {
+			active++
+		} else {
+			o.set(o.led(slot), free, freeBrightness)
+		}
+	}
+	o.metrics.resourcesActive.Set(float64(active))
+	err := o.show()
+	o.publish()
+	o.persist()
+	if o.stale {
+		o.reportHidden()
+	}
+	o.stale = false
+	return err
+}
+
+// redraw must be called with resourceLock held. It draws the resources as
+// they are now, without flashing, once the watches have synced.
+func (o *ControllerObj) redraw() {
+	if o.syncing == 0 {
+		o.quiet = true
+		o.dirty = false
+		o.updateBlinkt()
+		o.quiet = false
+	}
+}
+
+// prune drops deleted resources from resourceList and returns the flashes of
+// the visible ones where they were, except those still lingering. It filters
+// in a single pass of its own, before anything moves, so every deleted
+// resource flashes at the slot it was shown in however many are deleted at
+// once, and the remaining ones keep their order.
+func (o *ControllerObj) prune() []pendingFlash {
+	var flashes []pendingFlash
+	live := o.resourceList[:0]
+	now := o.clock.Now()
+	for i, r := range o.resourceList {
+		if r.state != deleted {
+			live = append(live, r)
+			continue
+		}
+		if slot, visible := o.slot(i); visible && o.perResource() && !o.InvertSlots && r.lingerUntil.IsZero() {
+			flashes = append(flashes, pendingFlash{o.led(slot), r.color, o.brightnessOf(&r), o.FlashPatterns[r.color]})
+		}
+		if o.lingers() && (r.lingerUntil.IsZero() || now.Before(r.lingerUntil)) {
+			if r.lingerUntil.IsZero() {
+				r.lingerUntil = now.Add(o.DeleteLinger)
+			}
+			live = append(live, r)
+		}
+	}
+	o.resourceList = live
+	return flashes
+}
+
+// Refresh redraws the strip as it should be right now, without flashing,
+// for instance after the board lost power. It returns the driver's error if
+// the strip couldn't be shown.
+func (o *ControllerObj) Refresh() error {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.quiet = true
+	defer func() { o.quiet = false }()
+	o.dirty = false
+	o.forget()
+	return o.updateBlinkt()
+}
+
+// sortResources orders resourceList with less, falling back to the keys
+// when Less panics.
+func (o *ControllerObj) sortResources() {
+	err := safely("less function", func() {
+		sort.SliceStable(o.resourceList, func(i, j int) bool {
+			return o.less(&o.resourceList[i], &o.resourceList[j], o.Less)
+		})
+	})
 	if err != nil {
-		log.Panicln(err.Error())
+		o.logger.Warn("Sorting resources by key", "error", err)
+		sort.SliceStable(o.resourceList, func(i, j int) bool {
+			return o.less(&o.resourceList[i], &o.resourceList[j], nil)
+		})
+	}
+}
+
+// reportOverflow must be called with resourceLock held, once the resources
+// are sorted but before they are drawn. When resources were added since the
+// last render, it counts every one left without room on the strip: those
+// just added and those they pushed off it.
+func (o *ControllerObj) reportOverflow() {
+	if o.ScrollInterval > 0 {
+		return
+	}
+	adds := false
+	for i := range o.resourceList {
+		if !o.resourceList[i].overflowChecked {
+			adds = true
+		}
+	}
+	if !adds {
+		return
+	}
+	for i := range o.resourceList {
+		r := &o.resourceList[i]
+		if r.state == deleted {
+			continue
+		}
+		added := !r.overflowChecked
+		r.overflowChecked = true
+		if _, visible := o.slot(i); !visible && (added || r.shown) {
+			o.metrics.overflow.Inc()
+			o.emit(Event{EventOverflow, r.key, "", r.color})
+		}
+	}
+}
+
+// SetBrightness changes the brightness of every LED without one of its own,
+// clamped into [0, 1], and redraws the strip at the new level at once,
+// without flashing.
+func (o *ControllerObj) SetBrightness(brightness float64) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.brightness = clampBrightness(brightness)
+	o.redraw()
+}
+
+// Brightness is the brightness of every LED without one of its own, as set
+// at construction, by SetBrightness or by a config reload.
+func (o *ControllerObj) Brightness() float64 {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	return o.brightness
+}
+
+// prioritized reports whether any resource has a priority annotation.
+func (o *ControllerObj) prioritized() bool {
+	for i := range o.resourceList {
+		if o.resourceList[i].priority != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// less orders resources by namespace when grouping, then by priority, then
+// most recently changed first when sorting by activity, then with by, or by
+// key when by is nil.
+func (o *ControllerObj) less(a, b *resource, by LessFunc) bool {
+	if o.GroupByNamespace && a.namespace != b.namespace {
+		return a.namespace < b.namespace
+	}
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	if o.SortByActivity && !a.lastChange.Equal(b.lastChange) {
+		return a.lastChange.After(b.lastChange)
+	}
+	if by != nil {
+		return by(a.obj, b.obj)
+	}
+	return a.key < b.key
+}
+
+// renderResources gives every visible resource its own LEDs. A resource that
+// was hidden and moves onto the strip, because one before it went away,
+// flashes like a new one; resources paged in by scrolling don't.
+func (o *ControllerObj) renderResources(lit []bool) {
+	for _, i := range o.visibleResources() {
+		r := &o.resourceList[i]
+		slot, visible := o.slot(i)
+		promoted := visible && !r.shown && o.ScrollInterval == 0
+		r.shown = visible
+		color, brightness := r.color, o.brightnessOf(r)
+		if r.state == deleted {
+			color = o.DeleteColor
+		}
+		if brightness == 0 || color == blinkt.Off {
+			color, brightness = blinkt.Off, 0
+		}
+		if visible && brightness > 0 && !o.quiet && r.state != deleted {
+			switch {
+			case r.state == updated && o.TransitionDuration > 0:
+				o.fade(o.led(slot), r.prevColor, r.color, o.brightnessOf(r))
+			case (r.state != unchanged || promoted) && o.mayFlash(r):
+				flash := r.color
+				if o.FlashColor != "" {
+					flash = o.FlashColor
+				}
+				o.flash(o.led(slot), flash, o.brightnessOf(r), o.FlashPatterns[r.color])
+			}
+		}
+		for _, slot := range o.slotsOf(i) {
+			o.set(o.led(slot), color, brightness)
+			lit[slot] = true
+		}
+	}
+	if !o.stale {
+		return
+	}
+	for i := range o.resourceList {
+		if _, visible := o.slot(i); !visible {
+			o.resourceList[i].shown = false
+		}
+		if o.resourceList[i].state != deleted {
+			o.resourceList[i].state = unchanged
+		}
+	}
+}
+
+// mayFlash reports whether r is allowed to flash now, and if so records that
+// it did.
+func (o *ControllerObj) mayFlash(r *resource) bool {
+	if o.MinFlashInterval > 0 {
+		now := o.clock.Now()
+		if now.Sub(r.lastFlash) < o.MinFlashInterval {
+			return false
+		}
+		r.lastFlash = now
+	}
+	return true
+}
+
+// renderInverted turns off the LEDs resources take, leaving the free ones to
+// be lit by updateBlinkt.
+func (o *ControllerObj) renderInverted(lit []bool) {
+	for i := range o.resourceList {
+		for _, slot := range o.slotsOf(i) {
+			o.set(o.led(slot), blinkt.Off, 0)
+			lit[slot] = true
+		}
+		o.resourceList[i].state = unchanged
+	}
+}
+
+func (o *ControllerObj) renderOverflow() {
+	color := o.OverflowColor
+	if o.overflowBlink {
+		color = blinkt.Off
+	}
+	for _, m := range o.overflowMarkers() {
+		brightness := o.brightness * math.Min(1, float64(m.hidden)/float64(o.slots()))
+		o.set(o.led(m.slot), color, brightness)
+	}
+}
+
+func (o *ControllerObj) blinkOverflow(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if len(o.overflowMarkers()) > 0 && !o.paused {
+				o.overflowBlink = !o.overflowBlink
+				o.renderOverflow()
+				o.show()
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// blinkAlerts turns the LEDs of resources with an alert color off and on
+// again. Renders in between draw them steady, so a resource that leaves the
+// alert color simply stops blinking.
+func (o *ControllerObj) blinkAlerts(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if !o.paused && o.perResource() && !o.InvertSlots {
+				o.alertBlink = !o.alertBlink
+				for i := range o.resourceList {
+					r := &o.resourceList[i]
+					if r.state == deleted || !o.alerts[r.color] {
+						continue
+					}
+					for _, slot := range o.slotsOf(i) {
+						if o.alertBlink {
+							o.set(o.led(slot), blinkt.Off, 0)
+						} else {
+							o.set(o.led(slot), r.color, o.brightnessOf(r))
+						}
+					}
+				}
+				o.show()
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// flash blinks led through pattern, or FlashCount times without one, and
+// leaves it off. It skips LEDs that would flash at no brightness or in Off:
+// there would be nothing to see.
+func (o *ControllerObj) flash(led int, color string, brightness float64, pattern FlashPattern) {
+	if brightness <= 0 || color == blinkt.Off || o.quiet {
+		return
+	}
+	if pattern == nil {
+		if o.FlashCount <= 0 {
+			return
+		}
+		pattern = steadyFlash(o.FlashCount, o.FlashDuration)
+	}
+	o.play(led, color, brightness, pattern)
+}
+
+const transitionSteps = 20
+
+func (o *ControllerObj) fade(led int, from, to string, brightness float64) {
+	for step := 1; step < transitionSteps; step++ {
+		o.set(led, blendColors(from, to, float64(step)/transitionSteps), brightness)
+		o.show()
+		o.clock.Sleep(o.TransitionDuration / transitionSteps)
+	}
+}
+
+func (o *ControllerObj) brightnessOf(r *resource) float64 {
+	if r.brightness == defaultBrightness {
+		return o.brightness
 	}
-	return key
+	return r.brightness
+}
+
+func clampBrightness(brightness float64) float64 {
+	return math.Max(0, math.Min(1, brightness))
+}
+
+// keyFunc turns a panic in the key function into an error, like safeColor.
+// The key function is given the object inside a tombstone, not the
+// tombstone itself.
+func (o *ControllerObj) keyFunc(obj interface{}) (key string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("key function panicked: %v", r)
+		}
+	}()
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok && tombstone.Obj == nil {
+		return tombstone.Key, nil
+	}
+	return o.KeyFunc(unwrap(obj))
+}
+
+// safeColor turns a panic in colorFunc into an error so a bad object can't
+// take down the informer.
+func safeColor(colorFunc ColorFuncE, obj interface{}) (color string, err error) {
+	if perr := safely("color function", func() { color, err = colorFunc(obj) }); perr != nil {
+		return "", perr
+	}
+	return color, err
+}
+
+// safely runs a user supplied function through f, turning a panic into an
+// error named after what panicked.
+func safely(what string, f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s panicked: %v", what, r)
+		}
+	}()
+	f()
+	return nil
 }
@@ -0,0 +1,696 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestUpdateBeforeAdd(t *testing.T) {
+	o, d := newTestController(t)
+	h := watchHandler(o, constant(blinkt.Red))
+
+	h.OnUpdate(pod("default", "a"), pod("default", "a"))
+	if got, want := keys(o), []string{"default/a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("resources %v after an update before any add, want %v", got, want)
+	}
+	if got := d.colors()[0]; got != blinkt.Red {
+		t.Errorf("LED 0 is %s, want %s", got, blinkt.Red)
+	}
+
+	d.reset()
+	h.OnDelete(pod("default", "unknown"))
+	if got, want := keys(o), []string{"default/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resources %v after deleting an unknown key, want %v", got, want)
+	}
+	if n := d.count("set"); n != 0 {
+		t.Errorf("deleting an unknown key set %d LEDs, want none", n)
+	}
+}
+
+func TestAddTwiceSameKey(t *testing.T) {
+	o, d := newTestController(t)
+	h := watchHandler(o, constant(blinkt.Red))
+
+	h.OnAdd(pod("default", "a"))
+	h.OnAdd(pod("default", "a"))
+	if got := len(keys(o)); got != 1 {
+		t.Fatalf("%d resources after adding the same key twice, want 1", got)
+	}
+	if got := d.colors()[1]; got != blinkt.Off {
+		t.Errorf("LED 1 is %s, want it off", got)
+	}
+}
+
+func TestPanickingBrightnessFunc(t *testing.T) {
+	o, _ := newTestController(t)
+	h := handlerOf(func() {
+		o.WatchWithBrightness(&cache.ListWatch{}, &v1.Pod{}, 0, constant(blinkt.Red), func(obj interface{}) float64 {
+			panic("boom")
+		}, nil)
+	})
+	h.OnAdd(pod("default", "a"))
+	if got := keys(o); len(got) != 0 {
+		t.Errorf("resources %v, want the object skipped", got)
+	}
+}
+
+func TestPanickingTickFunc(t *testing.T) {
+	o, _ := newTestController(t)
+	h := watchHandler(o, constant(blinkt.Red))
+	h.OnAdd(pod("default", "a"))
+	o.resourceLock.Lock()
+	o.reevaluate(func(obj interface{}) bool { panic("boom") })
+	o.resourceLock.Unlock()
+	if got, want := keys(o), []string{"default/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resources %v, want %v", got, want)
+	}
+}
+
+func TestPanickingLessFunc(t *testing.T) {
+	o, d := newTestController(t, WithLessFunc(func(a, b interface{}) bool { panic("boom") }))
+	h := watchHandler(o, func(obj interface{}) string {
+		if obj.(*v1.Pod).Name == "a" {
+			return blinkt.Blue
+		}
+		return blinkt.Red
+	})
+	h.OnAdd(pod("default", "b"))
+	h.OnAdd(pod("default", "a"))
+	if got, want := keys(o), []string{"default/a", "default/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resources %v, want them ordered by key", got)
+	}
+	if got := d.colors()[:2]; !reflect.DeepEqual(got, []string{blinkt.Blue, blinkt.Red}) {
+		t.Errorf("LEDs %v, want blue then red", got)
+	}
+}
+
+func TestPanickingColorFunc(t *testing.T) {
+	o, d := newTestController(t)
+	h := watchHandler(o, func(obj interface{}) string {
+		if obj.(*v1.Pod).Name == "bad" {
+			var p *v1.Pod
+			return string(p.Status.Phase)
+		}
+		return blinkt.Red
+	})
+
+	h.OnAdd(pod("default", "bad"))
+	h.OnAdd(pod("default", "good"))
+	if got, want := keys(o), []string{"default/good"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resources %v, want %v", got, want)
+	}
+	if got := d.colors()[0]; got != blinkt.Red {
+		t.Errorf("LED 0 is %s, want %s", got, blinkt.Red)
+	}
+}
+
+// eventually polls cond until it holds, failing after a while.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition never held")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBackgroundOutlivesFirstWatch(t *testing.T) {
+	o, d := newTestController(t, WithIdleAnimation(true))
+	stop := make(chan struct{})
+	close(stop)
+	handlerOf(func() {
+		o.Watch(&cache.ListWatch{}, &v1.Pod{}, 0, constant(blinkt.Red), stop)
+	})
+
+	// Give a goroutine tied to stop the time to see it closed.
+	time.Sleep(10 * time.Millisecond)
+	d.reset()
+	eventually(t, func() bool { return d.count("show") > 0 })
+
+	o.Stop()
+	d.reset()
+	time.Sleep(300 * time.Millisecond)
+	if n := d.count("show"); n != 0 {
+		t.Errorf("idle animation showed %d frames after Stop, want none", n)
+	}
+}
+
+func TestHeartbeatLeavesNoSlots(t *testing.T) {
+	opts := []Option{WithLEDCount(1), WithHeartbeat(0, blinkt.Blue)}
+	if _, err := newController(1, newFakeDriver(maxLEDCount), opts); err == nil {
+		t.Error("a heartbeat on the only LED was accepted, want an error")
+	}
+}
+
+func TestOverflowMarkerLeavesNoSlots(t *testing.T) {
+	opts := []Option{WithLEDCount(2), WithHeartbeat(0, blinkt.Blue), WithOverflowColor(blinkt.Red)}
+	if _, err := newController(1, newFakeDriver(maxLEDCount), opts); err == nil {
+		t.Error("an overflow marker on the only LED was accepted, want an error")
+	}
+}
+
+func TestKeyFuncNonMetadataObject(t *testing.T) {
+	o, _ := newTestController(t)
+	if key, err := o.keyFunc("not an object"); err == nil {
+		t.Errorf("got key %q for a string, want an error", key)
+	}
+
+	h := watchHandler(o, constant(blinkt.Red))
+	h.OnAdd(42)
+	h.OnUpdate(42, 42)
+	h.OnDelete(42)
+	if got := keys(o); len(got) != 0 {
+		t.Errorf("resources %v, want objects without metadata skipped", got)
+	}
+}
+
+func TestKeyFuncPanics(t *testing.T) {
+	o, _ := newTestController(t, WithKeyFunc(func(obj interface{}) (string, error) {
+		return obj.(*v1.Pod).Name, nil
+	}))
+	if key, err := o.keyFunc("not a pod"); err == nil {
+		t.Errorf("got key %q from a panicking key function, want an error", key)
+	}
+}
+
+func TestClampBrightness(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{-0.5, 0},
+		{0, 0},
+		{0.3, 0.3},
+		{1, 1},
+		{1.7, 1},
+	}
+	for _, test := range tests {
+		if got := clampBrightness(test.in); got != test.want {
+			t.Errorf("clampBrightness(%v) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestPanickingBarFunc(t *testing.T) {
+	o, d := newTestController(t, WithBar(func() float64 { panic("boom") }, blinkt.Blue))
+	h := watchHandler(o, constant(blinkt.Red))
+	h.OnAdd(pod("default", "a"))
+	if got := d.colors()[0]; got != blinkt.Off {
+		t.Errorf("LED 0 is %s, want an empty bar", got)
+	}
+}
+
+func TestIdleAnimationLeavesBarAlone(t *testing.T) {
+	o, d := newTestController(t, WithIdleAnimation(true), WithBar(func() float64 { return 0.5 }, blinkt.Blue))
+	watchHandler(o, constant(blinkt.Red))
+	time.Sleep(300 * time.Millisecond)
+	o.Stop()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range d.calls {
+		if c.op == "set" && c.color != blinkt.Blue && c.color != blinkt.Off {
+			t.Fatalf("LED %d set to %s in bar mode, want only the bar", c.pixel, c.color)
+		}
+	}
+}
+
+func TestResumeWhileSyncing(t *testing.T) {
+	o, d := newTestController(t)
+	h := watchHandler(o, constant(blinkt.Red))
+	o.resourceLock.Lock()
+	o.syncing++
+	o.resourceLock.Unlock()
+	h.OnAdd(pod("default", "a"))
+
+	d.reset()
+	o.resourceLock.Lock()
+	o.togglePause()
+	o.togglePause()
+	o.resourceLock.Unlock()
+	if n := d.count("show"); n != 0 {
+		t.Errorf("resuming before the caches synced showed %d frames, want none", n)
+	}
+}
+
+func TestDeletePromotesHiddenResource(t *testing.T) {
+	const cyan = "00FFFF"
+	o, d := newTestController(t, WithFlash(2, time.Millisecond))
+	h := watchHandler(o, func(obj interface{}) string {
+		if obj.(*v1.Pod).Name == "r9" {
+			return cyan
+		}
+		return blinkt.Red
+	})
+	for i := 1; i <= 9; i++ {
+		h.OnAdd(pod("default", fmt.Sprintf("r%d", i)))
+	}
+	if got := d.colors()[7]; got != blinkt.Red {
+		t.Fatalf("LED 7 is %s before the delete, want r8's red", got)
+	}
+
+	d.reset()
+	h.OnDelete(pod("default", "r3"))
+	if got, want := d.sent("set", 7), []string{cyan, blinkt.Off, cyan, blinkt.Off, cyan}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LED 7 was set to %v, want r9 flashing as it moves onto the strip", got)
+	}
+	if got := d.sent("set", 6); !reflect.DeepEqual(got, []string{blinkt.Red}) {
+		t.Errorf("LED 6 was set to %v, want r8's red", got)
+	}
+}
+
+func TestPanickingOverflowFunc(t *testing.T) {
+	calls := make(chan []string, 2)
+	o, _ := newTestController(t, WithLEDCount(1), WithOverflowFunc(func(hidden []string) {
+		calls <- hidden
+		if len(hidden) == 1 {
+			panic("boom")
+		}
+	}))
+	h := watchHandler(o, constant(blinkt.Red))
+	defer o.Stop()
+	h.OnAdd(pod("default", "a"))
+	h.OnAdd(pod("default", "b"))
+	if got := <-calls; !reflect.DeepEqual(got, []string{"default/b"}) {
+		t.Fatalf("hidden %v, want [default/b]", got)
+	}
+	h.OnAdd(pod("default", "c"))
+	select {
+	case got := <-calls:
+		if want := []string{"default/b", "default/c"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("hidden %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the overflow function was not called again after it panicked")
+	}
+}
+
+func TestPanickingCallbacks(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"weight", []Option{WithWeightFunc(func(obj interface{}) int { panic("boom") })}},
+		{"pulse rate", []Option{WithPulseRateFunc(func(obj interface{}) time.Duration { panic("boom") })}},
+		{"intensity", []Option{WithMonochrome("", func(obj interface{}) float64 { panic("boom") })}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o, _ := newTestController(t, test.opts...)
+			h := watchHandler(o, constant(blinkt.Red))
+			h.OnAdd(pod("default", "a"))
+			if got := keys(o); len(got) != 0 {
+				t.Errorf("resources %v, want the object skipped", got)
+			}
+		})
+	}
+}
+
+func TestDeleteTombstone(t *testing.T) {
+	podsOnly := func(obj interface{}) string {
+		obj.(*v1.Pod).GetName()
+		return blinkt.Red
+	}
+	tests := []struct {
+		name string
+		obj  interface{}
+	}{
+		{"with object", cache.DeletedFinalStateUnknown{Key: "default/a", Obj: pod("default", "a")}},
+		{"without object", cache.DeletedFinalStateUnknown{Key: "default/a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o, d := newTestController(t)
+			h := watchHandler(o, podsOnly)
+			h.OnAdd(pod("default", "a"))
+			h.OnAdd(pod("default", "b"))
+			h.OnDelete(test.obj)
+			if got, want := keys(o), []string{"default/b"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("resources %v after the tombstone, want %v", got, want)
+			}
+			if got := d.colors()[1]; got != blinkt.Off {
+				t.Errorf("LED 1 is %s, want it off", got)
+			}
+		})
+	}
+}
+
+func TestMinFlashInterval(t *testing.T) {
+	clock := newFakeClock()
+	o, _ := newTestController(t, WithClock(clock), WithFlash(1, time.Millisecond), WithMinFlashInterval(time.Second))
+	color := blinkt.Red
+	h := watchHandler(o, func(obj interface{}) string { return color })
+	clock.sleeps()
+	h.OnAdd(pod("default", "a"))
+	if got := len(clock.sleeps()); got != 2 {
+		t.Fatalf("added resource slept %d times, want one flash of 2", got)
+	}
+
+	color = blinkt.Blue
+	h.OnUpdate(pod("default", "a"), pod("default", "a"))
+	color = blinkt.Red
+	h.OnUpdate(pod("default", "a"), pod("default", "a"))
+	if got := len(clock.sleeps()); got != 0 {
+		t.Errorf("rapid color changes slept %d times, want no flash", got)
+	}
+
+	clock.Advance(time.Second)
+	color = blinkt.Blue
+	h.OnUpdate(pod("default", "a"), pod("default", "a"))
+	if got := len(clock.sleeps()); got != 2 {
+		t.Errorf("change after the interval slept %d times, want one flash of 2", got)
+	}
+}
+
+func TestNoFlashAtZeroBrightness(t *testing.T) {
+	clock := newFakeClock()
+	o, _ := newTestController(t, WithClock(clock), WithFlash(2, time.Millisecond))
+	h := handlerOf(func() {
+		o.WatchWithBrightness(&cache.ListWatch{}, &v1.Pod{}, 0, constant(blinkt.Red), func(obj interface{}) float64 { return 0 }, nil)
+	})
+	clock.sleeps()
+
+	h.OnAdd(pod("default", "a"))
+	h.OnDelete(pod("default", "a"))
+	if got := clock.sleeps(); len(got) != 0 {
+		t.Errorf("zero-brightness resource slept %v, want no flash", got)
+	}
+
+	o.Add("b", blinkt.Off)
+	o.Delete("b")
+	if got := clock.sleeps(); len(got) != 0 {
+		t.Errorf("resource colored off slept %v, want no flash", got)
+	}
+}
+
+func TestEvictNewLowestPriority(t *testing.T) {
+	o, d := newTestController(t, WithFlash(1, time.Millisecond), WithMaxResources(2, EvictLowestPriority))
+	events := o.Events()
+	h := watchHandler(o, constant(blinkt.Red))
+	high := func(name string) *v1.Pod {
+		p := pod("default", name)
+		p.Annotations = map[string]string{PriorityAnnotation: "1"}
+		return p
+	}
+	h.OnAdd(high("a"))
+	h.OnAdd(high("b"))
+	for len(events) > 0 {
+		<-events
+	}
+
+	d.reset()
+	h.OnAdd(pod("default", "c"))
+	if got, want := keys(o), []string{"default/a", "default/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resources %v, want %v", got, want)
+	}
+	if n := d.count("set"); n != 0 {
+		t.Errorf("evicting the new resource made %d sets, want none", n)
+	}
+	if len(events) != 0 {
+		t.Errorf("evicting the new resource emitted %v, want no events", <-events)
+	}
+}
+
+func TestSteadyRenderSkipsLayout(t *testing.T) {
+	compared := 0
+	o, d := newTestController(t, WithLessFunc(func(a, b interface{}) bool {
+		compared++
+		return false
+	}))
+	h := watchHandler(o, constant(blinkt.Red))
+	for i := 0; i < 20; i++ {
+		h.OnAdd(pod("default", fmt.Sprintf("p%02d", i)))
+	}
+
+	compared = 0
+	d.reset()
+	o.Refresh()
+	if compared != 0 {
+		t.Errorf("a refresh sorted the resources with %d comparisons, want none", compared)
+	}
+	if got := d.colors()[0]; got != blinkt.Red {
+		t.Errorf("LED 0 is %s after the refresh, want %s", got, blinkt.Red)
+	}
+
+	h.OnDelete(pod("default", "p00"))
+	if compared == 0 {
+		t.Error("the render after a delete did not sort the resources")
+	}
+}
+
+func TestPixelCounterFewerPixels(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithLEDCount(maxLEDCount)}} {
+		d := countedDriver{newFakeDriver(4)}
+		o, err := newController(1, d, append([]Option{WithLogOutput(ioutil.Discard), WithFlash(0, 0)}, opts...))
+		if err != nil {
+			t.Fatalf("newController: %v", err)
+		}
+		if o.ledCount != 4 {
+			t.Errorf("ledCount is %d, want the driver's 4 pixels", o.ledCount)
+		}
+		for i := 0; i < 6; i++ {
+			o.Add(fmt.Sprintf("r%d", i), blinkt.Red)
+		}
+		for _, c := range d.calls {
+			if c.op == "set" && c.pixel >= 4 {
+				t.Fatalf("set pixel %d on a driver with 4", c.pixel)
+			}
+		}
+		if got, want := d.colors(), []string{blinkt.Red, blinkt.Red, blinkt.Red, blinkt.Red}; !reflect.DeepEqual(got, want) {
+			t.Errorf("LEDs are %v, want %v", got, want)
+		}
+	}
+}
+
+func TestReverseClampedLEDCount(t *testing.T) {
+	d := countedDriver{newFakeDriver(maxLEDCount)}
+	o, err := newController(1, d, []Option{WithLogOutput(ioutil.Discard), WithFlash(0, 0), WithLEDCount(16), WithReverse(true)})
+	if err != nil {
+		t.Fatalf("newController: %v", err)
+	}
+	o.Add("a", blinkt.Red)
+	if got := d.colors()[maxLEDCount-1]; got != blinkt.Red {
+		t.Errorf("LED %d is %s, want the first resource reversed onto it", maxLEDCount-1, got)
+	}
+}
+
+// TestSetBrightnessConcurrentEvents is meant for go test -race: brightness
+// goes up and down while resources are added, updated and deleted.
+func TestSetBrightnessConcurrentEvents(t *testing.T) {
+	o, d := newTestController(t, WithFlash(0, 0))
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := fmt.Sprintf("%d-%d", g, i%5)
+				o.Add(key, blinkt.Red)
+				o.Update(key, blinkt.Blue)
+				if i%3 == 0 {
+					o.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			o.SetBrightness(float64(i%2) * 0.5)
+			o.Brightness()
+		}
+	}()
+	wg.Wait()
+
+	o.Add("last", blinkt.Red)
+	o.SetBrightness(0.25)
+	if got := o.Brightness(); got != 0.25 {
+		t.Errorf("Brightness is %v, want 0.25", got)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last := map[int]driverCall{}
+	for _, c := range d.calls {
+		if c.op == "set" {
+			last[c.pixel] = c
+		}
+	}
+	for led, c := range last {
+		if c.color != blinkt.Off && c.brightness != 0.25 {
+			t.Errorf("LED %d shows %s at %v, want 0.25 once brightness settled", led, c.color, c.brightness)
+		}
+	}
+}
+
+func TestFlashColor(t *testing.T) {
+	const white = "FFFFFF"
+	o, d := newTestController(t, WithFlash(1, time.Millisecond), WithFlashColor(white))
+	d.reset()
+	o.Add("a", blinkt.Red)
+	if got, want := d.sent("set", 0), []string{white, blinkt.Off, blinkt.Red}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LED 0 was set to %v on add, want a flash in %s then %s", got, white, blinkt.Red)
+	}
+
+	d.reset()
+	o.Add("a", blinkt.Blue)
+	if got, want := d.sent("set", 0), []string{white, blinkt.Off, blinkt.Blue}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LED 0 was set to %v on update, want a flash in %s then %s", got, white, blinkt.Blue)
+	}
+}
+
+func TestDeleteLinger(t *testing.T) {
+	clock := newFakeClock()
+	o, d := newTestController(t, WithClock(clock), WithFlash(0, 0), WithDeleteLinger(time.Second, ""))
+	o.Add("a", blinkt.Blue)
+	o.Delete("a")
+	if got := d.colors()[0]; got != blinkt.Red {
+		t.Fatalf("LED 0 is %s right after the delete, want it to linger in %s", got, blinkt.Red)
+	}
+
+	clock.Advance(time.Second)
+	o.resourceLock.Lock()
+	o.expire(clock.Now())
+	o.resourceLock.Unlock()
+	if got := d.colors()[0]; got != blinkt.Off {
+		t.Errorf("LED 0 is %s once the linger expired, want %s", got, blinkt.Off)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	colors := []string{blinkt.Red, "00FF00", blinkt.Blue, "FFFFFF"}
+	tests := []struct {
+		name    string
+		deleted []int
+	}{
+		{"first", []int{0}},
+		{"middle", []int{1}},
+		{"last", []int{3}},
+		{"two adjacent", []int{1, 2}},
+		{"first and last", []int{0, 3}},
+	}
+	for _, test := range tests {
+		o, d := newTestController(t, WithClock(newFakeClock()), WithFlash(1, time.Millisecond))
+		for i, color := range colors {
+			o.Add(fmt.Sprintf("r%d", i), color)
+		}
+		d.reset()
+
+		// Delete them all before a single render, as a resync would.
+		o.resourceLock.Lock()
+		o.syncing++
+		gone := map[int]bool{}
+		for _, i := range test.deleted {
+			o.deleteResource(o.getResource(directSource, fmt.Sprintf("r%d", i)))
+			gone[i] = true
+		}
+		o.syncing--
+		o.updateBlinkt()
+		o.resourceLock.Unlock()
+
+		var wantKeys []string
+		wantFrame := make([]string, maxLEDCount)
+		for i := range wantFrame {
+			wantFrame[i] = blinkt.Off
+		}
+		for i, color := range colors {
+			if gone[i] {
+				flash := []string{color, blinkt.Off}
+				if got := d.sent("set", i); len(got) < len(flash) || !reflect.DeepEqual(got[:len(flash)], flash) {
+					t.Errorf("%s: LED %d was set to %v, want r%d flashing where it was: %v...", test.name, i, got, i, flash)
+				}
+				continue
+			}
+			wantFrame[len(wantKeys)] = color
+			wantKeys = append(wantKeys, fmt.Sprintf("r%d", i))
+		}
+		if got := keys(o); !reflect.DeepEqual(got, wantKeys) {
+			t.Errorf("%s: resources %v, want %v", test.name, got, wantKeys)
+		}
+		if got := d.colors(); !reflect.DeepEqual(got, wantFrame) {
+			t.Errorf("%s: LEDs %v, want %v", test.name, got, wantFrame)
+		}
+	}
+}
+
+func TestSortByActivity(t *testing.T) {
+	clock := newFakeClock()
+	o, d := newTestController(t, WithClock(clock), WithFlash(0, 0), WithSortByActivity(true))
+	for i := 0; i < 10; i++ {
+		o.Add(fmt.Sprintf("r%d", i), blinkt.Red)
+		clock.Advance(time.Second)
+	}
+	if got := keys(o)[0]; got != "r9" {
+		t.Fatalf("slot 0 holds %s, want the last added r9", got)
+	}
+	if got := keys(o)[9]; got != "r0" {
+		t.Fatalf("the last resource is %s, want r0 pushed off the strip", got)
+	}
+
+	o.Update("r0", blinkt.Blue)
+	if got := keys(o)[0]; got != "r0" {
+		t.Errorf("slot 0 holds %s after r0 changed, want r0", got)
+	}
+	if got := d.colors()[0]; got != blinkt.Blue {
+		t.Errorf("LED 0 is %s, want r0's %s", got, blinkt.Blue)
+	}
+	if got := keys(o)[maxLEDCount]; got != "r2" {
+		t.Errorf("the first resource off the strip is %s, want r2 pushed off", got)
+	}
+}
+
+// BenchmarkRender renders a strip of n resources, steady or right after one
+// of them changed. Steady renders should cost the same however many
+// resources are hidden.
+func BenchmarkRender(b *testing.B) {
+	for _, n := range []int{8, 1000, 10000} {
+		for _, steady := range []bool{true, false} {
+			name := fmt.Sprintf("resources=%d/steady=%v", n, steady)
+			b.Run(name, func(b *testing.B) {
+				o, err := newController(1, newFakeDriver(maxLEDCount), []Option{
+					WithClock(newFakeClock()),
+					WithLogOutput(ioutil.Discard),
+					WithFlash(0, 0),
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				// Pretend to sync so that adding doesn't render every time.
+				o.syncing++
+				for i := 0; i < n; i++ {
+					o.Add(fmt.Sprintf("r%05d", i), blinkt.Red)
+				}
+				o.resourceLock.Lock()
+				defer o.resourceLock.Unlock()
+				o.syncing--
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					o.stale = !steady
+					o.updateBlinkt()
+				}
+			})
+		}
+	}
+}
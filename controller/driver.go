@@ -0,0 +1,120 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+// driverRetries bounds how many times a failed Show is retried, waiting
+// twice as long each time, before the frame is given up.
+const (
+	driverRetries = 3
+	driverBackoff = 10 * time.Millisecond
+)
+
+type blinktStrip struct {
+	b *blinkt.Blinkt
+}
+
+// NewBlinktDriver adapts a Blinkt from the blinkt library, whose calls
+// never fail, to BlinktDriver.
+func NewBlinktDriver(b *blinkt.Blinkt) BlinktDriver {
+	return blinktStrip{b}
+}
+
+func (s blinktStrip) Set(pixel int, color string, brightness float64) error {
+	s.b.Set(pixel, color, brightness)
+	return nil
+}
+
+func (s blinktStrip) Show() error {
+	s.b.Show()
+	return nil
+}
+
+func (s blinktStrip) Cleanup(color string, brightness float64) error {
+	s.b.Cleanup(color, brightness)
+	return nil
+}
+
+// driverError logs and counts a failed driver call. The controller carries
+// on: a bad frame is replaced by the next one.
+func (o *ControllerObj) driverError(op string, err error) {
+	o.logger.Warn("Blinkt driver call failed", "op", op, "error", err)
+	o.metrics.driverErrors.WithLabelValues(op).Inc()
+}
+
+// pixel is what an LED was last set to.
+type pixel struct {
+	color      string
+	brightness float64
+}
+
+// set skips LEDs already set to color and brightness when SkipUnchanged is
+// on.
+func (o *ControllerObj) set(led int, color string, brightness float64) {
+	p := pixel{color, brightness}
+	if o.SkipUnchanged {
+		if last, ok := o.shadow[led]; ok && last == p {
+			return
+		}
+	}
+	if err := o.blinkt.Set(led, color, brightness); err != nil {
+		o.driverError("set", err)
+		delete(o.shadow, led)
+		return
+	}
+	if o.SkipUnchanged {
+		if o.shadow == nil {
+			o.shadow = make(map[int]pixel, o.ledCount)
+		}
+		o.shadow[led] = p
+	}
+	o.pending = true
+}
+
+// forget drops what set remembers, for when the strip may not show it any
+// more, so the next frame is written in full.
+func (o *ControllerObj) forget() {
+	o.shadow = nil
+	o.pending = true
+}
+
+// show retries a failed Show with exponential backoff, and returns the last
+// error if it never succeeds. With SkipUnchanged, it does nothing unless an
+// LED was set since the last successful Show.
+func (o *ControllerObj) show() error {
+	if o.SkipUnchanged && !o.pending {
+		return nil
+	}
+	backoff := driverBackoff
+	for attempt := 0; ; attempt++ {
+		err := o.blinkt.Show()
+		if err == nil {
+			o.pending = false
+			return nil
+		}
+		o.driverError("show", err)
+		if attempt == driverRetries {
+			o.logger.Error("Giving up showing the strip", "attempts", attempt+1)
+			return err
+		}
+		o.clock.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/elafargue/blinkt"
+)
+
+func TestSkipUnchangedSteadyBoard(t *testing.T) {
+	o, d := newTestController(t, WithFlash(0, 0), WithSkipUnchanged(true))
+	o.Add("a", blinkt.Red)
+	o.Add("b", blinkt.Blue)
+
+	d.reset()
+	o.resourceLock.Lock()
+	o.updateBlinkt()
+	o.resourceLock.Unlock()
+	if n := len(d.calls); n != 0 {
+		t.Errorf("repainting a steady board made %d driver calls, want none", n)
+	}
+
+	o.Update("b", blinkt.Red)
+	if got, want := d.count("set"), 1; got != want {
+		t.Errorf("changing one LED made %d sets, want %d", got, want)
+	}
+	if got, want := d.count("show"), 1; got != want {
+		t.Errorf("changing one LED made %d shows, want %d", got, want)
+	}
+}
+
+// BenchmarkSteadyRepaint repaints a full board that doesn't change and
+// reports the driver calls each repaint makes.
+func BenchmarkSteadyRepaint(b *testing.B) {
+	for _, skip := range []bool{false, true} {
+		b.Run(fmt.Sprintf("skipUnchanged=%v", skip), func(b *testing.B) {
+			d := newFakeDriver(maxLEDCount)
+			o, err := newController(1, d, []Option{
+				WithClock(newFakeClock()),
+				WithLogOutput(ioutil.Discard),
+				WithFlash(0, 0),
+				WithSkipUnchanged(skip),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			for i := 0; i < maxLEDCount; i++ {
+				o.Add(fmt.Sprintf("r%d", i), blinkt.Red)
+			}
+			o.resourceLock.Lock()
+			defer o.resourceLock.Unlock()
+			d.reset()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				o.updateBlinkt()
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(len(d.calls))/float64(b.N), "calls/op")
+		})
+	}
+}
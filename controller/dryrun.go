@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+// DryRunBlinkt is a BlinktDriver that never touches any hardware. It logs
+// every LED that changes when the strip is shown, flashes included, so the
+// decisions of the controller can be checked from its logs.
+type DryRunBlinkt struct {
+	logger  Logger
+	colors  []string
+	levels  []float64
+	changed []bool
+}
+
+func NewDryRunBlinkt(logger Logger, pixels int) *DryRunBlinkt {
+	d := &DryRunBlinkt{
+		logger:  logger,
+		colors:  make([]string, pixels),
+		levels:  make([]float64, pixels),
+		changed: make([]bool, pixels),
+	}
+	for i := range d.colors {
+		d.colors[i] = "000000"
+	}
+	return d
+}
+
+func (d *DryRunBlinkt) Set(pixel int, color string, brightness float64) error {
+	if pixel >= 0 && pixel < len(d.colors) && (d.colors[pixel] != color || d.levels[pixel] != brightness) {
+		d.colors[pixel] = color
+		d.levels[pixel] = brightness
+		d.changed[pixel] = true
+	}
+	return nil
+}
+
+func (d *DryRunBlinkt) Show() error {
+	for i, changed := range d.changed {
+		if changed {
+			d.logger.Info("Dry run set", "pixel", i, "color", d.colors[i], "brightness", d.levels[i])
+			d.changed[i] = false
+		}
+	}
+	return nil
+}
+
+func (d *DryRunBlinkt) Cleanup(color string, brightness float64) error {
+	d.logger.Info("Dry run cleanup", "color", color, "brightness", brightness)
+	return nil
+}
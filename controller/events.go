@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+type EventType string
+
+const (
+	EventAdded    EventType = "added"
+	EventUpdated  EventType = "updated"
+	EventDeleted  EventType = "deleted"
+	EventOverflow EventType = "overflow"
+)
+
+// Event describes a change to a resource. OldColor is empty for added
+// resources and NewColor is empty for deleted ones. An overflow event names
+// a resource an add left without room on the strip.
+type Event struct {
+	Type     EventType
+	Key      string
+	OldColor string
+	NewColor string
+}
+
+// eventBuffer is how many events Events can hold before new ones are dropped.
+const eventBuffer = 64
+
+// Events returns a channel of resource changes. Events are dropped when the
+// channel is full, so a slow reader never holds up rendering. The channel is
+// closed by Stop.
+func (o *ControllerObj) Events() <-chan Event {
+	return o.events
+}
+
+// emit must be called with resourceLock held.
+func (o *ControllerObj) emit(e Event) {
+	if o.eventsClosed {
+		return
+	}
+	select {
+	case o.events <- e:
+	default:
+		o.logger.Debug("Dropping event", "type", e.Type, "key", e.Key)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "sort"
+
+// EvictionPolicy picks which resource to stop tracking once there are more
+// than MaxResources.
+type EvictionPolicy int
+
+const (
+	// EvictOldest drops the resource that was added first.
+	EvictOldest EvictionPolicy = iota
+	// EvictLowestPriority drops the resource with the lowest priority
+	// annotation, the oldest one among equals.
+	EvictLowestPriority
+)
+
+type resourceID struct {
+	source int
+	key    string
+}
+
+// evict must be called with resourceLock held. It deletes resources until
+// no more than MaxResources are left. An evicted resource stays out, its
+// updates ignored, until its object is deleted.
+func (o *ControllerObj) evict() {
+	if o.MaxResources <= 0 {
+		return
+	}
+	for {
+		live, victim := 0, -1
+		for i := range o.resourceList {
+			r := &o.resourceList[i]
+			if r.state == deleted {
+				continue
+			}
+			live++
+			if victim < 0 || o.evictsBefore(r, &o.resourceList[victim]) {
+				victim = i
+			}
+		}
+		if live <= o.MaxResources {
+			return
+		}
+		r := &o.resourceList[victim]
+		o.logger.Info("Evicting resource", "key", r.key, "max", o.MaxResources)
+		o.evicted[resourceID{r.source, r.key}] = true
+		o.deleteResource(r)
+	}
+}
+
+// admits must be called with resourceLock held. Once MaxResources are
+// tracked, a new resource that the policy would pick as the victim is
+// evicted straight away, before it is shown or announced.
+func (o *ControllerObj) admits(r *resource) bool {
+	if o.MaxResources <= 0 {
+		return true
+	}
+	live := 0
+	for i := range o.resourceList {
+		other := &o.resourceList[i]
+		if other.state == deleted {
+			continue
+		}
+		if o.evictsBefore(other, r) {
+			return true
+		}
+		live++
+	}
+	if live < o.MaxResources {
+		return true
+	}
+	o.logger.Info("Evicting resource", "key", r.key, "max", o.MaxResources)
+	o.evicted[resourceID{r.source, r.key}] = true
+	return false
+}
+
+func (o *ControllerObj) evictsBefore(a, b *resource) bool {
+	if o.Eviction == EvictLowestPriority && a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.seq < b.seq
+}
+
+// removeResource must be called with resourceLock held. It deletes the
+// resource with the given key, and forgets it was evicted.
+func (o *ControllerObj) removeResource(source int, key string) {
+	delete(o.evicted, resourceID{source, key})
+	if r := o.getResource(source, key); r != nil {
+		o.deleteResource(r)
+	}
+}
+
+// evictedKeys lists the keys of the evicted resources, sorted.
+func (o *ControllerObj) evictedKeys() []string {
+	keys := make([]string, 0, len(o.evicted))
+	for id := range o.evicted {
+		keys = append(keys, id.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,327 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// driverCall is one call made to a fakeDriver.
+type driverCall struct {
+	op         string
+	pixel      int
+	color      string
+	brightness float64
+}
+
+// fakeDriver records every call made to it and the frame last shown.
+type fakeDriver struct {
+	mu     sync.Mutex
+	calls  []driverCall
+	leds   []string
+	frame  []string
+	shows  int
+	pixels int
+}
+
+func newFakeDriver(pixels int) *fakeDriver {
+	d := &fakeDriver{
+		leds:   make([]string, pixels),
+		frame:  make([]string, pixels),
+		pixels: pixels,
+	}
+	for i := range d.leds {
+		d.leds[i] = blinkt.Off
+		d.frame[i] = blinkt.Off
+	}
+	return d
+}
+
+func (d *fakeDriver) Set(led int, color string, brightness float64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, driverCall{"set", led, color, brightness})
+	if led >= 0 && led < len(d.leds) {
+		d.leds[led] = color
+	}
+	return nil
+}
+
+func (d *fakeDriver) Show() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, driverCall{op: "show"})
+	copy(d.frame, d.leds)
+	d.shows++
+	return nil
+}
+
+func (d *fakeDriver) Cleanup(color string, brightness float64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, driverCall{"cleanup", -1, color, brightness})
+	return nil
+}
+
+// colors returns the color of every LED in the frame last shown.
+func (d *fakeDriver) colors() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.frame...)
+}
+
+// count returns how many calls of op were made.
+func (d *fakeDriver) count(op string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, c := range d.calls {
+		if c.op == op {
+			n++
+		}
+	}
+	return n
+}
+
+// sent returns the colors of every op call made to led, in order.
+func (d *fakeDriver) sent(op string, led int) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var colors []string
+	for _, c := range d.calls {
+		if c.op == op && c.pixel == led {
+			colors = append(colors, c.color)
+		}
+	}
+	return colors
+}
+
+func (d *fakeDriver) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = nil
+	d.shows = 0
+}
+
+// countedDriver is a fakeDriver that reports how many pixels it has.
+type countedDriver struct {
+	*fakeDriver
+}
+
+func (d countedDriver) Pixels() int {
+	return d.pixels
+}
+
+// fakeClock only moves when Sleep or Advance is called. Tickers and timers
+// fire from Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	slept   []time.Duration
+	tickers []*fakeTicker
+	timers  []*fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Sleep returns at once, moving the time on without firing anything.
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slept = append(c.slept, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the time on by d, ticks the tickers that are due and runs
+// the functions of the timers that expired.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	for _, t := range c.tickers {
+		t.tick(now)
+	}
+	var due []*fakeTimer
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.expire(now) {
+			due = append(due, t)
+		} else if !t.done() {
+			timers = append(timers, t)
+		}
+	}
+	c.timers = timers
+	c.mu.Unlock()
+	for _, t := range due {
+		t.f()
+	}
+}
+
+// sleeps returns the durations passed to Sleep since the last call.
+func (c *fakeClock) sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slept := c.slept
+	c.slept = nil
+	return slept
+}
+
+type fakeTicker struct {
+	mu      sync.Mutex
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+}
+
+// tick sends at most one tick, like a time.Ticker whose reader fell behind.
+func (t *fakeTicker) tick(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped || t.period <= 0 || now.Before(t.next) {
+		return
+	}
+	for !now.Before(t.next) {
+		t.next = t.next.Add(t.period)
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+type fakeTimer struct {
+	mu      sync.Mutex
+	at      time.Time
+	f       func()
+	fired   bool
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	active := !t.fired && !t.stopped
+	t.stopped = true
+	return active
+}
+
+func (t *fakeTimer) expire(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.fired || t.stopped || now.Before(t.at) {
+		return false
+	}
+	t.fired = true
+	return true
+}
+
+func (t *fakeTimer) done() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.fired || t.stopped
+}
+
+func newTestController(t *testing.T, opts ...Option) (*ControllerObj, *fakeDriver) {
+	t.Helper()
+	d := newFakeDriver(maxLEDCount)
+	return NewControllerWithBlinkt(1, d, opts...).(*ControllerObj), d
+}
+
+// idleController is an informer that never lists or watches anything.
+type idleController struct{}
+
+func (idleController) Run(stopCh <-chan struct{})      {}
+func (idleController) HasSynced() bool                 { return true }
+func (idleController) LastSyncResourceVersion() string { return "" }
+
+// handlerOf calls watch with an informer that never runs, and returns the
+// event handlers watch gave it.
+func handlerOf(watch func()) cache.ResourceEventHandler {
+	var handler cache.ResourceEventHandler
+	newInformer = func(lw cache.ListerWatcher, objType runtime.Object, resync time.Duration, h cache.ResourceEventHandler) (cache.Store, cache.Controller) {
+		handler = h
+		return nil, idleController{}
+	}
+	defer func() { newInformer = cache.NewInformer }()
+	watch()
+	return handler
+}
+
+// watchHandler returns the event handlers Watch registers for colorFunc.
+func watchHandler(o *ControllerObj, colorFunc ColorFunc) cache.ResourceEventHandler {
+	return handlerOf(func() {
+		o.Watch(&cache.ListWatch{}, &v1.Pod{}, 0, colorFunc, nil)
+	})
+}
+
+func constant(color string) ColorFunc {
+	return func(obj interface{}) string { return color }
+}
+
+func pod(namespace, name string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+// keys returns the keys of resourceList in order.
+func keys(o *ControllerObj) []string {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	keys := make([]string, 0, len(o.resourceList))
+	for _, r := range o.resourceList {
+		keys = append(keys, r.key)
+	}
+	return keys
+}
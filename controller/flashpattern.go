@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+// FlashPattern is how long an LED is on, then off, then on again and so on
+// while it flashes. For instance {100ms, 100ms, 100ms} blinks twice.
+type FlashPattern []time.Duration
+
+func (p FlashPattern) check() error {
+	for _, d := range p {
+		if d <= 0 {
+			return fmt.Errorf("invalid flash pattern %v: every duration must be positive", p)
+		}
+	}
+	return nil
+}
+
+// steadyFlash is the pattern of count flashes of duration, on then off.
+func steadyFlash(count int, duration time.Duration) FlashPattern {
+	pattern := make(FlashPattern, 2*count)
+	for i := range pattern {
+		pattern[i] = duration
+	}
+	return pattern
+}
+
+// play drives led through pattern and leaves it off, to be shown by the
+// render that follows when the pattern ends on.
+func (o *ControllerObj) play(led int, color string, brightness float64, pattern FlashPattern) {
+	for i, d := range pattern {
+		if i%2 == 0 {
+			o.set(led, color, brightness)
+		} else {
+			o.set(led, blinkt.Off, 0)
+		}
+		o.show()
+		o.clock.Sleep(d)
+	}
+	if len(pattern)%2 == 1 {
+		o.set(led, blinkt.Off, 0)
+	}
+}
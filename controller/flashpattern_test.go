@@ -0,0 +1,75 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+func TestPlay(t *testing.T) {
+	clock := newFakeClock()
+	o, d := newTestController(t, WithClock(clock))
+	d.reset()
+	pattern := FlashPattern{100 * time.Millisecond, 50 * time.Millisecond, 200 * time.Millisecond}
+	o.resourceLock.Lock()
+	o.play(2, blinkt.Red, 0.5, pattern)
+	o.resourceLock.Unlock()
+
+	want := []driverCall{
+		{"set", 2, blinkt.Red, 0.5}, {op: "show"},
+		{"set", 2, blinkt.Off, 0}, {op: "show"},
+		{"set", 2, blinkt.Red, 0.5}, {op: "show"},
+		{"set", 2, blinkt.Off, 0},
+	}
+	if !reflect.DeepEqual(d.calls, want) {
+		t.Errorf("play made calls %v, want %v", d.calls, want)
+	}
+	if got := clock.sleeps(); !reflect.DeepEqual(got, []time.Duration(pattern)) {
+		t.Errorf("play slept %v, want the pattern %v", got, pattern)
+	}
+}
+
+func TestFlashPatterns(t *testing.T) {
+	double := FlashPattern{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond, 4 * time.Millisecond}
+	clock := newFakeClock()
+	o, d := newTestController(t, WithClock(clock), WithFlash(3, 10*time.Millisecond), WithFlashPatterns(map[string]FlashPattern{blinkt.Red: double}))
+	o.Add("a", blinkt.Red)
+	if got, want := d.sent("set", 0), []string{blinkt.Red, blinkt.Off, blinkt.Red, blinkt.Off, blinkt.Red}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LED 0 was set to %v, want the double blink %v", got, want)
+	}
+	if got := clock.sleeps(); !reflect.DeepEqual(got, []time.Duration(double)) {
+		t.Errorf("slept %v, want the pattern %v", got, double)
+	}
+
+	o.Add("b", blinkt.Blue)
+	if got, want := clock.sleeps(), []time.Duration(steadyFlash(3, 10*time.Millisecond)); !reflect.DeepEqual(got, want) {
+		t.Errorf("slept %v for a color without a pattern, want the usual flashes %v", got, want)
+	}
+}
+
+func TestFlashPatternCheck(t *testing.T) {
+	for _, p := range []FlashPattern{{0}, {time.Millisecond, -time.Millisecond}} {
+		if err := p.check(); err == nil {
+			t.Errorf("check accepted %v", p)
+		}
+	}
+	if err := (FlashPattern{time.Millisecond}).check(); err != nil {
+		t.Errorf("check rejected a valid pattern: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"math"
+)
+
+// gammaBlinkt applies gamma correction to the brightness and color channels
+// on their way to the strip, so that perceived intensity scales evenly.
+type gammaBlinkt struct {
+	BlinktDriver
+	gamma float64
+}
+
+func (g gammaBlinkt) Set(pixel int, color string, brightness float64) error {
+	return g.BlinktDriver.Set(pixel, g.color(color), g.level(brightness))
+}
+
+func (g gammaBlinkt) level(v float64) float64 {
+	return math.Pow(clampBrightness(v), g.gamma)
+}
+
+func (g gammaBlinkt) color(color string) string {
+	r, gr, b, err := parseHex(color)
+	if err != nil {
+		return color
+	}
+	channel := func(c uint8) int {
+		return int(math.Round(255 * g.level(float64(c)/255)))
+	}
+	return fmt.Sprintf("%02X%02X%02X", channel(r), channel(gr), channel(b))
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGammaLevel(t *testing.T) {
+	g := gammaBlinkt{gamma: 2}
+	tests := []struct {
+		in, want float64
+	}{
+		{-1, 0},
+		{0, 0},
+		{0.5, 0.25},
+		{1, 1},
+		{2, 1},
+	}
+	for _, test := range tests {
+		if got := g.level(test.in); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("level(%v) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestGammaColor(t *testing.T) {
+	g := gammaBlinkt{gamma: 2}
+	tests := []struct {
+		in, want string
+	}{
+		{"000000", "000000"},
+		{"FFFFFF", "FFFFFF"},
+		{"808080", "404040"},
+		{"FF8000", "FF4000"},
+		{"not a color", "not a color"},
+	}
+	for _, test := range tests {
+		if got := g.color(test.in); got != test.want {
+			t.Errorf("color(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestGammaSet(t *testing.T) {
+	d := newFakeDriver(maxLEDCount)
+	g := gammaBlinkt{d, 2}
+	g.Set(3, "808080", 0.5)
+	want := driverCall{"set", 3, "404040", 0.25}
+	if len(d.calls) != 1 || d.calls[0] != want {
+		t.Errorf("calls %v, want %v", d.calls, []driverCall{want})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HasSynced reports whether every watch has finished listing its objects.
+func (o *ControllerObj) HasSynced() bool {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	return o.synced && o.syncing == 0
+}
+
+// HealthHandler answers 200 while the controller is healthy and 503
+// otherwise, for use as a readiness or liveness probe. It is healthy once
+// the informer caches have synced and until it is stopped, and, with
+// WithHealthThreshold, as long as the strip was last shown within the
+// threshold.
+func (o *ControllerObj) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := o.healthy(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+func (o *ControllerObj) healthy() error {
+	select {
+	case <-o.stopCh:
+		return fmt.Errorf("stopped")
+	default:
+	}
+	if !o.HasSynced() {
+		return fmt.Errorf("informer caches not synced")
+	}
+	if o.HealthThreshold > 0 {
+		if since := o.clock.Now().Sub(o.driver.lastShown()); since > o.HealthThreshold {
+			return fmt.Errorf("strip last shown %v ago", since.Round(time.Second))
+		}
+	}
+	return nil
+}
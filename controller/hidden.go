@@ -0,0 +1,69 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+// reportHidden must be called with resourceLock held. It hands the keys of
+// the resources left off the strip, then those evicted, to notifyOverflow
+// whenever they change.
+// Only the latest list is kept, so a slow callback skips intermediate ones
+// rather than holding up rendering.
+func (o *ControllerObj) reportHidden() {
+	if o.OnOverflow == nil || !o.perResource() {
+		return
+	}
+	hidden := []string{}
+	for i := range o.resourceList {
+		if _, visible := o.slot(i); !visible {
+			hidden = append(hidden, o.resourceList[i].key)
+		}
+	}
+	hidden = append(hidden, o.evictedKeys()...)
+	if sameKeys(hidden, o.hidden) {
+		return
+	}
+	o.hidden = hidden
+	select {
+	case <-o.hiddenCh:
+	default:
+	}
+	o.hiddenCh <- hidden
+}
+
+// notifyOverflow calls OnOverflow without holding resourceLock, so the
+// callback may block or call back into the controller.
+func (o *ControllerObj) notifyOverflow(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case hidden := <-o.hiddenCh:
+			if err := safely("overflow function", func() { o.OnOverflow(hidden) }); err != nil {
+				o.logger.Warn("Ignoring overflow hook failure", "error", err)
+			}
+		}
+	}
+}
+
+func sameKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
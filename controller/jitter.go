@@ -0,0 +1,37 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"math/rand"
+	"os"
+	"time"
+)
+
+// jitterFactor draws the factor periods are stretched by, between 1 and
+// 1+ResyncJitter. It is seeded from the clock and the process ID, so it stays
+// the same within a run but differs between pods started together.
+func (o *ControllerObj) jitterFactor() float64 {
+	if o.ResyncJitter <= 0 {
+		return 1
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano() ^ int64(os.Getpid())))
+	return 1 + o.ResyncJitter*r.Float64()
+}
+
+// jittered stretches d by the controller's jitter factor.
+func (o *ControllerObj) jittered(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * o.jitter)
+}
@@ -0,0 +1,335 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sort"
+)
+
+// relayout recomputes where every resource sits on the strip. It must be
+// called whenever resourceList changes. Pinned resources take their slot
+// first, then stable resources keep theirs where they can, and everything
+// else fills the remaining positions in order. A weighted resource placed in
+// order waits for enough free positions in a row; a pinned or stable one
+// takes as many as are free after its slot.
+func (o *ControllerObj) relayout() {
+	o.positions = make([]int, len(o.resourceList))
+	o.widths = make([]int, len(o.resourceList))
+	o.markers = nil
+	if o.GroupByNamespace {
+		o.layoutGroups()
+	} else {
+		o.layoutSlots()
+	}
+	o.extent = 0
+	for i := range o.resourceList {
+		if end := o.positions[i] + o.widths[i]; end > o.extent {
+			o.extent = end
+		}
+	}
+	o.occupants = make([]int, o.extent)
+	for p := range o.occupants {
+		o.occupants[p] = -1
+	}
+	for i := range o.resourceList {
+		for p := o.positions[i]; p < o.positions[i]+o.widths[i]; p++ {
+			o.occupants[p] = i
+		}
+	}
+}
+
+func (o *ControllerObj) layoutSlots() {
+	taken := make(map[int]bool, len(o.resourceList))
+	claim := func(i, start int) {
+		o.positions[i] = start
+		n := 0
+		for n < o.weight(i) && !taken[start+n] {
+			taken[start+n] = true
+			n++
+		}
+		o.widths[i] = n
+	}
+	var pinned []int
+	for i, r := range o.resourceList {
+		o.positions[i] = -1
+		if r.pin >= 0 {
+			pinned = append(pinned, i)
+		}
+	}
+	sort.Slice(pinned, func(a, b int) bool {
+		return pinLess(o.resourceList[pinned[a]], o.resourceList[pinned[b]])
+	})
+	for _, i := range pinned {
+		if pin := o.resourceList[i].pin; !taken[pin] {
+			claim(i, pin)
+		}
+	}
+	if o.StableSlots {
+		for i, r := range o.resourceList {
+			if o.positions[i] < 0 && !taken[r.slot] {
+				claim(i, r.slot)
+			}
+		}
+	}
+	next := 0
+	for i := range o.resourceList {
+		if o.positions[i] < 0 {
+			for !free(taken, next, o.weight(i)) {
+				next++
+			}
+			claim(i, next)
+		}
+	}
+}
+
+// layoutGroups gives every namespace a band of consecutive positions, in
+// list order, which updateBlinkt sorts by namespace. Bands are one dark LED
+// apart when everything fits with the gaps. Otherwise, unless scrolling,
+// the strip is shared out between namespaces and those that don't fit their
+// band hide the rest behind an overflow marker of their own.
+func (o *ControllerObj) layoutGroups() {
+	groups := o.namespaceGroups()
+	demand := make([]int, len(groups))
+	total := 0
+	for g, group := range groups {
+		for _, i := range group {
+			demand[g] += o.weight(i)
+		}
+		total += demand[g]
+	}
+	gap := 0
+	if total+len(groups)-1 <= o.slots() {
+		gap = 1
+	}
+	bands := demand
+	if total > o.slots() && o.ScrollInterval == 0 {
+		bands = share(demand, o.slots())
+	}
+	start, hiddenAt := 0, o.slots()
+	for g, group := range groups {
+		room := bands[g]
+		overflow := demand[g] > room && o.OverflowColor != "" && o.perResource()
+		if overflow && room > 0 {
+			room--
+		}
+		next, hidden := start, 0
+		for _, i := range group {
+			w := o.weight(i)
+			if next+w > start+room {
+				o.positions[i], o.widths[i] = hiddenAt, w
+				hiddenAt += w
+				hidden++
+				continue
+			}
+			o.positions[i], o.widths[i] = next, w
+			next += w
+		}
+		if overflow && room < bands[g] {
+			o.markers = append(o.markers, marker{start + room, hidden})
+		}
+		start += bands[g] + gap
+	}
+}
+
+// namespaceGroups splits resourceList into runs of the same namespace.
+func (o *ControllerObj) namespaceGroups() [][]int {
+	var groups [][]int
+	for i, r := range o.resourceList {
+		if len(groups) == 0 || o.resourceList[i-1].namespace != r.namespace {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+	return groups
+}
+
+// share hands n positions out one at a time to every group still asking for
+// more, so small groups get all they need and the rest split what's left.
+func share(demand []int, n int) []int {
+	bands := make([]int, len(demand))
+	for n > 0 {
+		gave := false
+		for g := range demand {
+			if n > 0 && bands[g] < demand[g] {
+				bands[g]++
+				n--
+				gave = true
+			}
+		}
+		if !gave {
+			break
+		}
+	}
+	return bands
+}
+
+// visibleResources lists the resources with at least one LED on the strip
+// right now, left to right, without going through the hidden ones.
+func (o *ControllerObj) visibleResources() []int {
+	var visible []int
+	for slot := 0; slot < o.capacity(); slot++ {
+		p := slot + o.scrollOffset
+		if p >= len(o.occupants) {
+			break
+		}
+		if i := o.occupants[p]; i >= 0 && (len(visible) == 0 || visible[len(visible)-1] != i) {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+func free(taken map[int]bool, start, n int) bool {
+	for p := start; p < start+n; p++ {
+		if taken[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// weight is the number of positions the i-th resource asks for.
+func (o *ControllerObj) weight(i int) int {
+	w := o.resourceList[i].weight
+	if w < 1 {
+		return 1
+	}
+	if w > o.slots() {
+		return o.slots()
+	}
+	return w
+}
+
+func pinLess(a, b resource) bool {
+	if a.key != b.key {
+		return a.key < b.key
+	}
+	return a.source < b.source
+}
+
+// checkPin warns when r asks for a slot another resource already asked for.
+func (o *ControllerObj) checkPin(r resource) {
+	if r.pin < 0 {
+		return
+	}
+	for _, other := range o.resourceList {
+		if other.pin == r.pin && (other.source != r.source || other.key != r.key) {
+			winner := other.key
+			if pinLess(r, other) {
+				winner = r.key
+			}
+			o.logger.Warn("Slot pinned twice", "slot", r.pin, "key", r.key, "other", other.key, "winner", winner)
+			return
+		}
+	}
+}
+
+func (o *ControllerObj) freeSlot() int {
+	if !o.StableSlots {
+		return -1
+	}
+	used := make(map[int]bool, len(o.resourceList))
+	for _, r := range o.resourceList {
+		used[r.slot] = true
+		if r.pin >= 0 {
+			used[r.pin] = true
+		}
+	}
+	slot := 0
+	for used[slot] {
+		slot++
+	}
+	return slot
+}
+
+// position is where the i-th resource sits on the (possibly scrolled) strip.
+func (o *ControllerObj) position(i int) int {
+	return o.positions[i]
+}
+
+// slot is the LED showing the i-th resource, if it is currently visible.
+func (o *ControllerObj) slot(i int) (int, bool) {
+	slot := o.position(i) - o.scrollOffset
+	return slot, slot >= 0 && slot < o.capacity()
+}
+
+// slotsOf lists every visible LED the i-th resource takes.
+func (o *ControllerObj) slotsOf(i int) []int {
+	var slots []int
+	for p := o.positions[i]; p < o.positions[i]+o.widths[i]; p++ {
+		if slot := p - o.scrollOffset; slot >= 0 && slot < o.capacity() {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+func (o *ControllerObj) overflowing() bool {
+	return o.OverflowColor != "" && o.perResource() && o.span() > o.slots() && len(o.markers) == 0
+}
+
+// marker is an LED showing how many resources are hidden.
+type marker struct {
+	slot, hidden int
+}
+
+// overflowMarkers lists the overflow markers to draw: one per namespace
+// that ran out of room when grouping, or the last LED when the strip is full.
+func (o *ControllerObj) overflowMarkers() []marker {
+	if len(o.markers) > 0 {
+		return o.markers
+	}
+	if o.overflowing() {
+		return []marker{{o.slots() - 1, o.span() - o.capacity()}}
+	}
+	return nil
+}
+
+// perResource reports whether every resource gets its own LED, as opposed to
+// the aggregate and bar modes.
+func (o *ControllerObj) perResource() bool {
+	return !o.AggregateMode && o.BarFunc == nil
+}
+
+// capacity is the number of slots left for resources once the overflow
+// marker, if any, has taken the last one.
+func (o *ControllerObj) capacity() int {
+	if o.overflowing() {
+		return o.slots() - 1
+	}
+	return o.slots()
+}
+
+// slots is the number of LEDs available to resources.
+func (o *ControllerObj) slots() int {
+	if o.HeartbeatSlot >= 0 {
+		return o.ledCount - 1
+	}
+	return o.ledCount
+}
+
+// led maps a resource slot to the LED showing it, skipping the heartbeat.
+func (o *ControllerObj) led(slot int) int {
+	if o.HeartbeatSlot >= 0 && slot >= o.HeartbeatSlot {
+		return slot + 1
+	}
+	return slot
+}
+
+// span is the number of positions the resources occupy, including any gaps
+// left by stable or pinned slots.
+func (o *ControllerObj) span() int {
+	return o.extent
+}
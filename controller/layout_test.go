@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+)
+
+// weighted lays out resources of the given weights, none of them pinned.
+func weighted(t *testing.T, weights ...int) *ControllerObj {
+	o, _ := newTestController(t)
+	for i, w := range weights {
+		o.resourceList = append(o.resourceList, resource{key: string(rune('a' + i)), weight: w, pin: -1})
+	}
+	o.relayout()
+	return o
+}
+
+func TestLayoutSlotsWeights(t *testing.T) {
+	o := weighted(t, 2, 0, 3)
+	if want := []int{0, 2, 3}; !reflect.DeepEqual(o.positions, want) {
+		t.Errorf("positions %v, want %v", o.positions, want)
+	}
+	if want := []int{2, 1, 3}; !reflect.DeepEqual(o.widths, want) {
+		t.Errorf("widths %v, want %v: a weight below 1 counts as 1", o.widths, want)
+	}
+}
+
+func TestLayoutSlotsOversubscribed(t *testing.T) {
+	o := weighted(t, 3, 2, 4, 1)
+	if want := []int{0, 3, 5, 9}; !reflect.DeepEqual(o.positions, want) {
+		t.Errorf("positions %v, want %v", o.positions, want)
+	}
+	if got, want := o.slotsOf(2), []int{5, 6, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("c shows on %v, want the LEDs left: %v", got, want)
+	}
+	if _, visible := o.slot(3); visible {
+		t.Error("d is visible, want it past the end of the strip")
+	}
+}
+
+func TestLayoutSlotsWeightClamped(t *testing.T) {
+	o := weighted(t, 20, 1)
+	if want := []int{maxLEDCount, 1}; !reflect.DeepEqual(o.widths, want) {
+		t.Errorf("widths %v, want %v: no resource takes more than the strip", o.widths, want)
+	}
+	if got := o.slotsOf(1); len(got) != 0 {
+		t.Errorf("b shows on %v, want it hidden", got)
+	}
+}
+
+func TestLayoutSlotsWeightAroundPin(t *testing.T) {
+	o, _ := newTestController(t)
+	o.resourceList = []resource{
+		{key: "a", weight: 3, pin: -1},
+		{key: "b", weight: 2, pin: 2},
+	}
+	o.relayout()
+	if want := []int{4, 2}; !reflect.DeepEqual(o.positions, want) {
+		t.Errorf("positions %v, want %v: a waits for three free positions in a row", o.positions, want)
+	}
+}
+
+func TestShare(t *testing.T) {
+	tests := []struct {
+		demand []int
+		n      int
+		want   []int
+	}{
+		{[]int{5, 5, 5}, 8, []int{3, 3, 2}},
+		{[]int{1, 10}, 8, []int{1, 7}},
+		{[]int{2, 2}, 8, []int{2, 2}},
+		{[]int{4, 4, 4}, 2, []int{1, 1, 0}},
+	}
+	for _, test := range tests {
+		if got := share(test.demand, test.n); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("share(%v, %d) = %v, want %v", test.demand, test.n, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// RegisterLegend records what colors mean, keyed by color, so the display
+// can explain itself. Colors take the same forms as a ColorFunc returns;
+// registering a color again replaces its meaning.
+func (o *ControllerObj) RegisterLegend(legend map[string]string) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	for c, meaning := range legend {
+		color, err := parseColor(c)
+		if err != nil {
+			o.logger.Warn("Ignoring legend entry", "meaning", meaning, "error", err)
+			continue
+		}
+		o.legend[color] = meaning
+	}
+}
+
+// Legend returns a copy of the registered color meanings, keyed by RRGGBB
+// color.
+func (o *ControllerObj) Legend() map[string]string {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	legend := make(map[string]string, len(o.legend))
+	for color, meaning := range o.legend {
+		legend[color] = meaning
+	}
+	return legend
+}
+
+// LegendHandler serves the registered color meanings as a JSON object.
+func (o *ControllerObj) LegendHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(o.Legend()); err != nil {
+			o.logger.Error("Could not write legend", "error", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "time"
+
+// lingerTick is how often lingering resources are checked, and so how late
+// past DeleteLinger their LED may be freed.
+const lingerTick = 100 * time.Millisecond
+
+// lingers reports whether deleted resources stay on their LED for a while.
+func (o *ControllerObj) lingers() bool {
+	return o.DeleteLinger > 0 && o.perResource() && !o.InvertSlots
+}
+
+// expireLingering renders the strip again once a lingering resource's time
+// is up, which frees its LED.
+func (o *ControllerObj) expireLingering(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(lingerTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.Chan():
+			o.resourceLock.Lock()
+			o.expire(now)
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// expire must be called with resourceLock held. It renders once if any
+// lingering resource is due by now, so the next render prunes it.
+func (o *ControllerObj) expire(now time.Time) {
+	for _, r := range o.resourceList {
+		if r.state == deleted && !r.lingerUntil.IsZero() && !now.Before(r.lingerUntil) {
+			o.stale = true
+			o.render()
+			return
+		}
+	}
+}
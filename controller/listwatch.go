@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ListOptionsFunc narrows down what a ListWatch lists and watches, for
+// instance by setting a label or field selector.
+type ListOptionsFunc func(options *metav1.ListOptions)
+
+// NewPodListWatch lists and watches the pods in namespace, or in every
+// namespace when it is empty.
+func NewPodListWatch(client kubernetes.Interface, namespace string, tweaks ...ListOptionsFunc) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			tweak(&options, tweaks)
+			return client.CoreV1().Pods(namespace).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			tweak(&options, tweaks)
+			return client.CoreV1().Pods(namespace).Watch(options)
+		},
+	}
+}
+
+// NewNodeListWatch lists and watches the nodes of the cluster.
+func NewNodeListWatch(client kubernetes.Interface, tweaks ...ListOptionsFunc) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			tweak(&options, tweaks)
+			return client.CoreV1().Nodes().List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			tweak(&options, tweaks)
+			return client.CoreV1().Nodes().Watch(options)
+		},
+	}
+}
+
+// NewDeploymentListWatch lists and watches the deployments in namespace, or
+// in every namespace when it is empty.
+func NewDeploymentListWatch(client kubernetes.Interface, namespace string, tweaks ...ListOptionsFunc) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			tweak(&options, tweaks)
+			return client.AppsV1().Deployments(namespace).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			tweak(&options, tweaks)
+			return client.AppsV1().Deployments(namespace).Watch(options)
+		},
+	}
+}
+
+// NewStatefulSetListWatch lists and watches the stateful sets in namespace,
+// or in every namespace when it is empty.
+func NewStatefulSetListWatch(client kubernetes.Interface, namespace string, tweaks ...ListOptionsFunc) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			tweak(&options, tweaks)
+			return client.AppsV1().StatefulSets(namespace).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			tweak(&options, tweaks)
+			return client.AppsV1().StatefulSets(namespace).Watch(options)
+		},
+	}
+}
+
+func tweak(options *metav1.ListOptions, tweaks []ListOptionsFunc) {
+	for _, t := range tweaks {
+		t(options)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// lockedBlinkt serializes every call to the driver it wraps. Renders and
+// animations also hold resourceLock for a whole frame, so their frames don't
+// interleave; this lock makes sure no driver call can race another even if
+// it happens outside of a frame.
+type lockedBlinkt struct {
+	mu    sync.Mutex
+	b     BlinktDriver
+	clock Clock
+	shown time.Time
+}
+
+func (l *lockedBlinkt) Set(pixel int, color string, brightness float64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Set(pixel, color, brightness)
+}
+
+func (l *lockedBlinkt) Show() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.b.Show(); err != nil {
+		return err
+	}
+	l.shown = l.clock.Now()
+	return nil
+}
+
+// lastShown returns when Show last succeeded.
+func (l *lockedBlinkt) lastShown() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.shown
+}
+
+func (l *lockedBlinkt) Cleanup(color string, brightness float64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Cleanup(color, brightness)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// unsyncedDriver has no locking of its own, so the race detector catches
+// any call that lockedBlinkt lets through concurrently.
+type unsyncedDriver struct {
+	leds  [maxLEDCount]string
+	shows int
+}
+
+func (d *unsyncedDriver) Set(led int, color string, brightness float64) error {
+	d.leds[led] = color
+	return nil
+}
+
+func (d *unsyncedDriver) Show() error {
+	d.shows++
+	return nil
+}
+
+func (d *unsyncedDriver) Cleanup(color string, brightness float64) error {
+	for i := range d.leds {
+		d.leds[i] = color
+	}
+	return nil
+}
+
+// TestLockedConcurrentEvents is meant for go test -race: events, two
+// animations and calls made outside resourceLock all drive the strip at
+// once.
+func TestLockedConcurrentEvents(t *testing.T) {
+	o := NewControllerWithBlinkt(1, &unsyncedDriver{},
+		WithFlash(0, 0),
+		WithHeartbeat(7, blinkt.Blue),
+		WithIdleAnimation(true),
+	).(*ControllerObj)
+	h := handlerOf(func() {
+		o.Watch(&cache.ListWatch{}, &v1.Pod{}, 0, constant(blinkt.Red), nil)
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				p := pod("default", fmt.Sprintf("%d-%d", g, i%5))
+				h.OnAdd(p)
+				h.OnUpdate(p, p)
+				if i%3 == 0 {
+					h.OnDelete(p)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			o.blinkt.Set(i%maxLEDCount, blinkt.Red, 1)
+			o.blinkt.Show()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	wg.Wait()
+	o.Stop()
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+// Logger receives the controller's messages as a message and alternating
+// key/value pairs. A *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+type stdLogger struct {
+	out   *log.Logger
+	level LogLevel
+}
+
+// NewLogger returns a Logger that writes messages at level or above to the
+// standard logger as "LEVEL message key=value ...".
+func NewLogger(level LogLevel) Logger {
+	return stdLogger{level: level}
+}
+
+// NewLoggerTo is like NewLogger but writes to out.
+func NewLoggerTo(out *log.Logger, level LogLevel) Logger {
+	return stdLogger{out, level}
+}
+
+func (l stdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.print(LevelDebug, msg, keysAndValues)
+}
+
+func (l stdLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.print(LevelInfo, msg, keysAndValues)
+}
+
+func (l stdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.print(LevelWarn, msg, keysAndValues)
+}
+
+func (l stdLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.print(LevelError, msg, keysAndValues)
+}
+
+func (l stdLogger) print(level LogLevel, msg string, keysAndValues []interface{}) {
+	if level < l.level {
+		return
+	}
+	var line bytes.Buffer
+	line.WriteString(levelNames[level])
+	line.WriteString(" ")
+	line.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			fmt.Fprintf(&line, " %v=%v", keysAndValues[i], keysAndValues[i+1])
+		} else {
+			fmt.Fprintf(&line, " %v", keysAndValues[i])
+		}
+	}
+	if l.out != nil {
+		l.out.Println(line.String())
+	} else {
+		log.Println(line.String())
+	}
+}
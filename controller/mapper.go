@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "fmt"
+
+// IndexMapper maps the LED the controller draws on to the physical LED.
+type IndexMapper func(logicalIndex int) int
+
+// ReverseIndexMapper numbers the LEDs of a strip of ledCount from the other
+// end.
+func ReverseIndexMapper(ledCount int) IndexMapper {
+	return func(i int) int {
+		return ledCount - 1 - i
+	}
+}
+
+// mappedBlinkt moves every pixel through an IndexMapper on its way to the
+// strip.
+type mappedBlinkt struct {
+	BlinktDriver
+	mapper IndexMapper
+}
+
+func (m mappedBlinkt) Set(pixel int, color string, brightness float64) error {
+	return m.BlinktDriver.Set(m.mapper(pixel), color, brightness)
+}
+
+// checkMapper makes sure mapper sends every one of the ledCount LEDs to a
+// different LED of the strip.
+func checkMapper(mapper IndexMapper, ledCount int) error {
+	seen := make(map[int]int, ledCount)
+	for i := 0; i < ledCount; i++ {
+		p := mapper(i)
+		if p < 0 || p >= ledCount {
+			return fmt.Errorf("invalid index mapper: LED %d maps to %d, outside 0 to %d", i, p, ledCount-1)
+		}
+		if other, ok := seen[p]; ok {
+			return fmt.Errorf("invalid index mapper: LEDs %d and %d both map to %d", other, i, p)
+		}
+		seen[p] = i
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type metrics struct {
+	registry        *prometheus.Registry
+	events          *prometheus.CounterVec
+	resourcesActive prometheus.Gauge
+	overflow        prometheus.Counter
+	driverErrors    *prometheus.CounterVec
+	watchErrors     *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blinkt_events_total",
+			Help: "Number of add, update and delete events handled.",
+		}, []string{"type"}),
+		resourcesActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "blinkt_resources_active",
+			Help: "Number of LEDs currently showing a resource.",
+		}),
+		overflow: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blinkt_overflow_total",
+			Help: "Number of resources left off the strip because every LED was taken.",
+		}),
+		driverErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blinkt_driver_errors_total",
+			Help: "Number of failed calls to the Blinkt driver.",
+		}, []string{"op"}),
+		watchErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blinkt_watch_errors_total",
+			Help: "Number of failed lists and watches of Kubernetes resources.",
+		}, []string{"op"}),
+	}
+	m.registry.MustRegister(m.events, m.resourcesActive, m.overflow, m.driverErrors, m.watchErrors)
+	return m
+}
+
+// MetricsHandler serves the controller's Prometheus metrics.
+func (o *ControllerObj) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(o.metrics.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elafargue/blinkt"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/api/core/v1"
+)
+
+// overflows drains the events emitted so far and returns the keys of the
+// overflow ones.
+func overflows(o *ControllerObj) []string {
+	var keys []string
+	for {
+		select {
+		case e := <-o.Events():
+			if e.Type == EventOverflow {
+				keys = append(keys, e.Key)
+			}
+		default:
+			return keys
+		}
+	}
+}
+
+func overflowTotal(t *testing.T, o *ControllerObj) float64 {
+	var m dto.Metric
+	if err := o.metrics.overflow.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestOverflowAddedLast(t *testing.T) {
+	o, _ := newTestController(t, WithLEDCount(2))
+	h := watchHandler(o, constant(blinkt.Red))
+	for _, name := range []string{"a", "b", "c"} {
+		h.OnAdd(pod("default", name))
+	}
+	if got, want := overflows(o), []string{"default/c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("overflowed %v, want %v", got, want)
+	}
+	if got := overflowTotal(t, o); got != 1 {
+		t.Errorf("overflow total %v, want 1", got)
+	}
+}
+
+func TestOverflowPushedOff(t *testing.T) {
+	byName := func(a, b interface{}) bool {
+		return a.(*v1.Pod).Name < b.(*v1.Pod).Name
+	}
+	o, _ := newTestController(t, WithLEDCount(2), WithLessFunc(byName))
+	h := watchHandler(o, constant(blinkt.Red))
+	for _, name := range []string{"b", "c", "a"} {
+		h.OnAdd(pod("default", name))
+	}
+	if got, want := overflows(o), []string{"default/c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("overflowed %v, want %v: a sorts first and pushes c off", got, want)
+	}
+	h.OnUpdate(pod("default", "b"), pod("default", "b"))
+	if got := overflows(o); len(got) != 0 {
+		t.Errorf("overflowed %v on an update, want nothing", got)
+	}
+	if got := overflowTotal(t, o); got != 1 {
+		t.Errorf("overflow total %v, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+// MirrorDriver shows the same picture on several boards: every call is
+// repeated on each of them, in order, and the first error is returned once
+// every board was called.
+type MirrorDriver struct {
+	boards []BlinktDriver
+}
+
+// NewMirrorDriver mirrors the strip onto boards.
+func NewMirrorDriver(boards ...BlinktDriver) *MirrorDriver {
+	return &MirrorDriver{boards: boards}
+}
+
+func (m *MirrorDriver) Set(pixel int, color string, brightness float64) error {
+	var first error
+	for _, b := range m.boards {
+		if err := b.Set(pixel, color, brightness); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m *MirrorDriver) Show() error {
+	var first error
+	for _, b := range m.boards {
+		if err := b.Show(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m *MirrorDriver) Cleanup(color string, brightness float64) error {
+	var first error
+	for _, b := range m.boards {
+		if err := b.Cleanup(color, brightness); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
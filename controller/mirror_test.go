@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+	"k8s.io/api/core/v1"
+)
+
+func TestMirrorDriverSameCalls(t *testing.T) {
+	a, b := newFakeDriver(maxLEDCount), newFakeDriver(maxLEDCount)
+	o := NewControllerWithBlinkt(1, NewMirrorDriver(a, b), WithFlash(2, time.Millisecond)).(*ControllerObj)
+	h := watchHandler(o, func(obj interface{}) string {
+		if obj.(*v1.Pod).Name == "a" {
+			return blinkt.Red
+		}
+		return blinkt.Blue
+	})
+	h.OnAdd(pod("default", "a"))
+	h.OnAdd(pod("default", "b"))
+	h.OnUpdate(pod("default", "a"), pod("default", "a"))
+	h.OnDelete(pod("default", "b"))
+	o.Cleanup()
+
+	if len(a.calls) == 0 {
+		t.Fatal("no calls reached the boards")
+	}
+	if !reflect.DeepEqual(a.calls, b.calls) {
+		t.Errorf("boards got different calls:\n%v\n%v", a.calls, b.calls)
+	}
+}
+
+// failingDriver records its calls like a fakeDriver but fails every Set.
+type failingDriver struct {
+	*fakeDriver
+}
+
+var errBoard = errors.New("board unplugged")
+
+func (d failingDriver) Set(led int, color string, brightness float64) error {
+	d.fakeDriver.Set(led, color, brightness)
+	return errBoard
+}
+
+func TestMirrorDriverError(t *testing.T) {
+	a, b := newFakeDriver(maxLEDCount), newFakeDriver(maxLEDCount)
+	m := NewMirrorDriver(failingDriver{a}, b)
+	if err := m.Set(0, blinkt.Red, 1); err != errBoard {
+		t.Errorf("got error %v, want %v", err, errBoard)
+	}
+	if !reflect.DeepEqual(a.calls, b.calls) {
+		t.Errorf("the board after a failing one got %v, want %v", b.calls, a.calls)
+	}
+}
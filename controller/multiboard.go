@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "fmt"
+
+// MultiBoardDriver chains several boards into one strip: pixels 0 to 7 are
+// on the first board, 8 to 15 on the second, and so on.
+type MultiBoardDriver struct {
+	boards []BlinktDriver
+}
+
+// NewMultiBoardDriver chains boards in order. Each of them must drive a full
+// Blinkt of eight LEDs.
+func NewMultiBoardDriver(boards ...BlinktDriver) *MultiBoardDriver {
+	return &MultiBoardDriver{boards: boards}
+}
+
+// Pixels returns the number of LEDs across all boards. The controller uses
+// it as its default and maximum LED count.
+func (m *MultiBoardDriver) Pixels() int {
+	return len(m.boards) * maxLEDCount
+}
+
+func (m *MultiBoardDriver) Set(pixel int, color string, brightness float64) error {
+	b, p, err := m.board(pixel)
+	if err != nil {
+		return err
+	}
+	return b.Set(p, color, brightness)
+}
+
+func (m *MultiBoardDriver) Show() error {
+	var first error
+	for _, b := range m.boards {
+		if err := b.Show(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m *MultiBoardDriver) Cleanup(color string, brightness float64) error {
+	var first error
+	for _, b := range m.boards {
+		if err := b.Cleanup(color, brightness); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// board maps a pixel of the chain to a board and a pixel on it.
+func (m *MultiBoardDriver) board(pixel int) (BlinktDriver, int, error) {
+	if pixel < 0 || pixel >= m.Pixels() {
+		return nil, 0, fmt.Errorf("pixel %d is not between 0 and %d", pixel, m.Pixels()-1)
+	}
+	return m.boards[pixel/maxLEDCount], pixel % maxLEDCount, nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+// dimBlinkt scales the brightness of every call by factor. The factor is
+// only changed with resourceLock held, which every render also holds.
+type dimBlinkt struct {
+	BlinktDriver
+	factor float64
+}
+
+func (d *dimBlinkt) Set(pixel int, color string, brightness float64) error {
+	return d.BlinktDriver.Set(pixel, color, brightness*d.factor)
+}
+
+func (d *dimBlinkt) Cleanup(color string, brightness float64) error {
+	return d.BlinktDriver.Cleanup(color, brightness*d.factor)
+}
+
+// parseClock parses an HH:MM time of day into the time since midnight.
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: want HH:MM", clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// night reports whether now falls between NightStart and NightEnd, which may
+// wrap around midnight.
+func (o *ControllerObj) night(now time.Time) bool {
+	now = now.In(o.Location)
+	clock := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	if o.nightStart <= o.nightEnd {
+		return clock >= o.nightStart && clock < o.nightEnd
+	}
+	return clock >= o.nightStart || clock < o.nightEnd
+}
+
+// dimAtNight checks the time every minute and redraws the strip at
+// NightBrightness when the night starts, and back at full brightness when
+// it ends.
+func (o *ControllerObj) dimAtNight(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.Chan():
+			factor := 1.0
+			if o.night(now) {
+				factor = o.NightBrightness
+			}
+			o.resourceLock.Lock()
+			if factor != o.dimmer.factor {
+				o.logger.Info("Changing night dimming", "factor", factor)
+				o.dimmer.factor = factor
+				o.forget()
+				if o.syncing == 0 {
+					o.dirty = false
+					o.updateBlinkt()
+				}
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/elafargue/blinkt"
+)
+
+// pauseOnSignal toggles pause on every SIGUSR1. It is separate from
+// HandleSignals: pausing never stops the watches, and a stop signal while
+// paused still shuts down and cleans up the strip as usual.
+func (o *ControllerObj) pauseOnSignal(stopCh <-chan struct{}) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-sigs:
+			o.resourceLock.Lock()
+			o.togglePause()
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// SetPaused pauses or resumes the display, like SIGUSR1 does.
+func (o *ControllerObj) SetPaused(paused bool) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	if paused != o.paused {
+		o.togglePause()
+	}
+}
+
+// togglePause must be called with resourceLock held. While paused the
+// resources keep being tracked but nothing is drawn; resuming draws them as
+// they are now, or leaves them to the first render once the caches sync.
+func (o *ControllerObj) togglePause() {
+	o.paused = !o.paused
+	if o.paused {
+		o.logger.Info("Pausing the display", "blank", o.PauseBlank)
+		if o.PauseBlank {
+			for i := 0; i < o.ledCount; i++ {
+				o.set(i, blinkt.Off, 0)
+			}
+			o.show()
+		}
+		return
+	}
+	o.logger.Info("Resuming the display")
+	if o.syncing > 0 {
+		return
+	}
+	o.dirty = false
+	o.updateBlinkt()
+}
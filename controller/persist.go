@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFileDelay debounces writes to StateFile so a burst of events only
+// writes it once.
+const stateFileDelay = time.Second
+
+// restoreState lights the LEDs recorded in StateFile so the strip shows the
+// last known state straight away. The informers then reconcile it: the
+// first render after they sync redraws every LED from fresh data.
+func (o *ControllerObj) restoreState() {
+	data, err := ioutil.ReadFile(o.StateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			o.logger.Warn("Could not read state file", "file", o.StateFile, "error", err)
+		}
+		return
+	}
+	var state boardState
+	if err := json.Unmarshal(data, &state); err != nil {
+		o.logger.Warn("Ignoring corrupt state file", "file", o.StateFile, "error", err)
+		return
+	}
+	for _, r := range state.Resources {
+		if r.Slot < 0 || r.Slot >= o.ledCount || r.State == stateNames[deleted] {
+			continue
+		}
+		color, err := parseColor(r.Color)
+		if err != nil {
+			continue
+		}
+		o.set(r.Slot, color, o.brightness)
+	}
+	o.show()
+	o.logger.Info("Restored LEDs from state file", "file", o.StateFile, "resources", len(state.Resources))
+}
+
+// persist schedules a write of StateFile. It must be called with
+// resourceLock held.
+func (o *ControllerObj) persist() {
+	if o.StateFile == "" || o.saveTimer != nil {
+		return
+	}
+	o.saveTimer = o.clock.AfterFunc(stateFileDelay, func() {
+		o.resourceLock.Lock()
+		defer o.resourceLock.Unlock()
+		o.saveState()
+	})
+}
+
+// saveState must be called with resourceLock held. The file is replaced
+// atomically so a crash never leaves half of it behind.
+func (o *ControllerObj) saveState() {
+	if o.saveTimer != nil {
+		o.saveTimer.Stop()
+		o.saveTimer = nil
+	}
+	data, err := json.Marshal(o.state())
+	if err != nil {
+		o.logger.Error("Could not encode state", "error", err)
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(o.StateFile), filepath.Base(o.StateFile))
+	if err == nil {
+		_, err = tmp.Write(data)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), o.StateFile)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}
+	if err != nil {
+		o.logger.Error("Could not write state file", "file", o.StateFile, "error", err)
+	}
+}
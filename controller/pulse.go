@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+// PulseRateFunc returns how long a resource's LED stays on, then off, while
+// it pulses. Zero keeps it steady.
+type PulseRateFunc func(obj interface{}) time.Duration
+
+// pulseTick is how often pulsing LEDs are checked, and so the fastest they
+// can pulse.
+const pulseTick = 50 * time.Millisecond
+
+// pulse toggles the LED of every visible resource with a pulse rate once
+// its rate has elapsed. Renders in between draw them steady again.
+func (o *ControllerObj) pulse(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(pulseTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.Chan():
+			o.resourceLock.Lock()
+			if !o.paused && o.perResource() && !o.InvertSlots {
+				changed := false
+				for i := range o.resourceList {
+					r := &o.resourceList[i]
+					if r.pulse <= 0 || r.state == deleted || now.Before(r.nextPulse) {
+						continue
+					}
+					r.pulseOff = !r.pulseOff
+					r.nextPulse = now.Add(r.pulse)
+					for _, slot := range o.slotsOf(i) {
+						if r.pulseOff {
+							o.set(o.led(slot), blinkt.Off, 0)
+						} else {
+							o.set(o.led(slot), r.color, o.brightnessOf(r))
+						}
+						changed = true
+					}
+				}
+				if changed {
+					o.show()
+				}
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// config holds the settings that can be changed while the controller runs.
+// Missing fields keep their current value. Durations use time.ParseDuration
+// syntax, e.g. "50ms".
+type config struct {
+	Brightness      *float64 `json:"brightness"`
+	FlashCount      *int     `json:"flashCount"`
+	FlashDuration   string   `json:"flashDuration"`
+	RefreshInterval string   `json:"refreshInterval"`
+	CleanupColor    string   `json:"cleanupColor"`
+}
+
+// reload re-reads ConfigFile every time the process gets a SIGHUP.
+func (o *ControllerObj) reload(stopCh <-chan struct{}) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-sigs:
+			if err := o.loadConfig(); err != nil {
+				o.logger.Error("Ignoring invalid config", "file", o.ConfigFile, "error", err)
+			}
+		}
+	}
+}
+
+// loadConfig applies ConfigFile to the running controller. Nothing is
+// changed unless every setting in it is valid.
+func (o *ControllerObj) loadConfig() error {
+	data, err := ioutil.ReadFile(o.ConfigFile)
+	if err != nil {
+		return err
+	}
+	var c config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	if c.Brightness != nil && (*c.Brightness < 0 || *c.Brightness > 1) {
+		return fmt.Errorf("brightness %v is not between 0 and 1", *c.Brightness)
+	}
+	if c.FlashCount != nil && *c.FlashCount < 0 {
+		return fmt.Errorf("flash count %d is negative", *c.FlashCount)
+	}
+	flashDuration, err := parseConfigDuration(c.FlashDuration)
+	if err != nil {
+		return fmt.Errorf("flash duration: %v", err)
+	}
+	refreshInterval, err := parseConfigDuration(c.RefreshInterval)
+	if err != nil {
+		return fmt.Errorf("refresh interval: %v", err)
+	}
+	var cleanupColor string
+	if c.CleanupColor != "" {
+		if cleanupColor, err = parseColor(c.CleanupColor); err != nil {
+			return err
+		}
+	}
+
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	if c.Brightness != nil {
+		o.brightness = *c.Brightness
+	}
+	if c.FlashCount != nil {
+		o.FlashCount = *c.FlashCount
+	}
+	if flashDuration >= 0 {
+		o.FlashDuration = flashDuration
+	}
+	if refreshInterval >= 0 {
+		o.RefreshInterval = refreshInterval
+		select {
+		case o.reloaded <- struct{}{}:
+		default:
+		}
+	}
+	if cleanupColor != "" {
+		o.CleanupColor = cleanupColor
+	}
+	o.logger.Info("Reloaded config", "file", o.ConfigFile)
+	if o.syncing == 0 {
+		o.dirty = false
+		o.updateBlinkt()
+	}
+	return nil
+}
+
+// parseConfigDuration returns -1 for a missing duration.
+func parseConfigDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return -1, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%v is negative", d)
+	}
+	return d, nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "k8s.io/client-go/tools/cache"
+
+// WatchWithInformer is like Watch but takes its objects from informer
+// instead of listing and watching them itself, so several controllers can
+// share one cache and one connection to the API server. The informer
+// belongs to the caller, who must run it; the controller only adds its
+// event handlers, and ignores the events once stopCh is closed or Stop is
+// called. Resync and watch error handling are up to the informer.
+func (o *ControllerObj) WatchWithInformer(informer cache.SharedIndexInformer, colorFunc ColorFunc, stopCh <-chan struct{}) {
+	_, handler := o.newHandler(func(obj interface{}) (string, error) {
+		return colorFunc(obj), nil
+	}, nil)
+	// An informer that already synced hands its objects to the new handlers
+	// at once: count it as syncing first so they don't render one by one.
+	o.resourceLock.Lock()
+	o.syncing++
+	o.resourceLock.Unlock()
+	informer.AddEventHandler(o.untilStopped(handler, stopCh))
+	o.runSyncing(stopCh, sharedInformer{informer})
+}
+
+// sharedInformer is the cache.Controller of an informer someone else runs:
+// running it only waits until it should stop.
+type sharedInformer struct {
+	cache.SharedIndexInformer
+}
+
+func (s sharedInformer) Run(stopCh <-chan struct{}) {
+	<-stopCh
+}
+
+// untilStopped drops the events handler would get once stopCh is closed or
+// Stop is called, as handlers can't be taken off a shared informer.
+func (o *ControllerObj) untilStopped(handler cache.ResourceEventHandlerFuncs, stopCh <-chan struct{}) cache.ResourceEventHandlerFuncs {
+	stopped := func() bool {
+		select {
+		case <-stopCh:
+			return true
+		case <-o.stopCh:
+			return true
+		default:
+			return false
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if !stopped() {
+				handler.AddFunc(obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !stopped() {
+				handler.UpdateFunc(oldObj, newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if !stopped() {
+				handler.DeleteFunc(obj)
+			}
+		},
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+	"k8s.io/client-go/tools/cache"
+)
+
+// syncedInformer is a shared informer that synced before the controller
+// came along: adding a handler replays its objects at once.
+type syncedInformer struct {
+	cache.SharedIndexInformer
+	objs []interface{}
+}
+
+func (s syncedInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	for _, obj := range s.objs {
+		handler.OnAdd(obj)
+	}
+}
+
+func (s syncedInformer) HasSynced() bool {
+	return true
+}
+
+func TestWatchWithInformerSynced(t *testing.T) {
+	clock := newFakeClock()
+	o, d := newTestController(t, WithClock(clock), WithFlash(1, time.Millisecond))
+	informer := syncedInformer{objs: []interface{}{pod("default", "a"), pod("default", "b")}}
+	stop := make(chan struct{})
+	defer close(stop)
+	go o.WatchWithInformer(informer, constant(blinkt.Red), stop)
+
+	eventually(t, func() bool {
+		return reflect.DeepEqual(d.colors()[:2], []string{blinkt.Red, blinkt.Red})
+	})
+	if got := clock.sleeps(); len(got) != 0 {
+		t.Errorf("replaying the informer's objects slept %v, want them drawn at once without flashing", got)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import "github.com/elafargue/blinkt"
+
+// directSource owns the resources set through Add, Update and Delete, apart
+// from those of any watch.
+const directSource = -1
+
+// Add shows a resource that doesn't come from Kubernetes, so any event
+// source can drive the strip. It takes the same color forms as a ColorFunc.
+// Adding a key that already exists updates it. Call Run, even without any
+// watch, to start the animations and overflow handling.
+func (o *ControllerObj) Add(key, color string) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.setDirect(key, color)
+}
+
+// Update changes the color of a resource set with Add, adding it if needed.
+func (o *ControllerObj) Update(key, color string) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.setDirect(key, color)
+}
+
+// Delete removes a resource set with Add.
+func (o *ControllerObj) Delete(key string) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.removeResource(directSource, key)
+}
+
+func (o *ControllerObj) setDirect(key, color string) {
+	c, err := parseColor(color)
+	if err != nil {
+		o.logger.Warn("Turning off resource with an invalid color", "key", key, "error", err)
+		c = blinkt.Off
+	}
+	o.setResource(resource{
+		source:     directSource,
+		key:        key,
+		color:      c,
+		brightness: defaultBrightness,
+		pin:        -1,
+	})
+}
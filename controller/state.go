@@ -0,0 +1,124 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type resourceState struct {
+	Key   string `json:"key"`
+	Color string `json:"color"`
+	Slot  int    `json:"slot"`
+	State string `json:"state"`
+}
+
+type boardState struct {
+	Resources []resourceState `json:"resources"`
+	Off       []int           `json:"off"`
+}
+
+// state must be called with resourceLock held. Slots are LED indices;
+// resources that are not on the strip right now have a slot of -1.
+func (o *ControllerObj) state() boardState {
+	state := boardState{
+		Resources: make([]resourceState, 0, len(o.resourceList)),
+		Off:       []int{},
+	}
+	lit := make([]bool, o.slots())
+	for i, r := range o.resourceList {
+		led := -1
+		if slot, visible := o.slot(i); visible {
+			led = o.led(slot)
+		}
+		for _, slot := range o.slotsOf(i) {
+			lit[slot] = r.state != deleted || !r.lingerUntil.IsZero()
+		}
+		state.Resources = append(state.Resources, resourceState{r.key, r.color, led, stateNames[r.state]})
+	}
+	for _, m := range o.overflowMarkers() {
+		lit[m.slot] = true
+	}
+	for slot := range lit {
+		if !lit[slot] {
+			state.Off = append(state.Off, o.led(slot))
+		}
+	}
+	return state
+}
+
+// ResourceView is a copy of what the controller knows about one resource.
+type ResourceView struct {
+	Key   string
+	Color string
+	// Slot is the LED showing the resource, or -1 if it is hidden.
+	Slot    int
+	Visible bool
+}
+
+// Snapshot returns every resource currently tracked, in display order. It
+// is the in-process counterpart of StateHandler.
+func (o *ControllerObj) Snapshot() []ResourceView {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	return o.snapshot()
+}
+
+// IsVisible reports whether the resource with key is on the strip right now,
+// as opposed to hidden by overflow or not tracked at all.
+func (o *ControllerObj) IsVisible(key string) bool {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	for i, r := range o.resourceList {
+		if r.key != key || r.state == deleted {
+			continue
+		}
+		if _, visible := o.slot(i); visible {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot must be called with resourceLock held.
+func (o *ControllerObj) snapshot() []ResourceView {
+	views := make([]ResourceView, 0, len(o.resourceList))
+	for i, r := range o.resourceList {
+		if r.state == deleted {
+			continue
+		}
+		view := ResourceView{Key: r.key, Color: r.color, Slot: -1}
+		if slot, visible := o.slot(i); visible {
+			view.Slot = o.led(slot)
+			view.Visible = true
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// StateHandler serves what the controller believes each LED is showing as JSON.
+func (o *ControllerObj) StateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		o.resourceLock.Lock()
+		state := o.state()
+		o.resourceLock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(state); err != nil {
+			o.logger.Error("Could not write state", "error", err)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/elafargue/blinkt"
+)
+
+func TestIsVisible(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources int
+		opts      []Option
+		visible   int
+	}{
+		{"undersubscribed", 3, nil, 3},
+		{"full", maxLEDCount, nil, maxLEDCount},
+		{"oversubscribed", 12, nil, maxLEDCount},
+		{"oversubscribed small strip", 6, []Option{WithLEDCount(4)}, 4},
+	}
+	for _, test := range tests {
+		o, _ := newTestController(t, append([]Option{WithFlash(0, 0)}, test.opts...)...)
+		for i := 0; i < test.resources; i++ {
+			o.Add(fmt.Sprintf("r%02d", i), blinkt.Red)
+		}
+		for i := 0; i < test.resources; i++ {
+			key := fmt.Sprintf("r%02d", i)
+			if got, want := o.IsVisible(key), i < test.visible; got != want {
+				t.Errorf("%s: IsVisible(%s) = %v, want %v", test.name, key, got, want)
+			}
+		}
+		if o.IsVisible("unknown") {
+			t.Errorf("%s: IsVisible(unknown) = true for a key never added", test.name)
+		}
+	}
+}
+
+func TestIsVisibleAfterDelete(t *testing.T) {
+	o, _ := newTestController(t, WithFlash(0, 0))
+	for i := 0; i < 9; i++ {
+		o.Add(fmt.Sprintf("r%d", i), blinkt.Red)
+	}
+	if o.IsVisible("r8") {
+		t.Fatalf("r8 is visible on a full strip")
+	}
+	o.Delete("r0")
+	if o.IsVisible("r0") {
+		t.Errorf("r0 is still visible after it was deleted")
+	}
+	if !o.IsVisible("r8") {
+		t.Errorf("r8 is hidden after r0 made room for it")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// streamBuffer is how many renders a client may fall behind before it is
+// dropped.
+const streamBuffer = 16
+
+// streamWriteTimeout is how long a client may take to accept one state
+// document before it is dropped.
+const streamWriteTimeout = 10 * time.Second
+
+// publish must be called with resourceLock held. It never blocks: clients
+// that can't keep up are disconnected.
+func (o *ControllerObj) publish() {
+	if len(o.snapshotSubscribers) > 0 {
+		views := o.snapshot()
+		for updates := range o.snapshotSubscribers {
+			select {
+			case updates <- views:
+			default:
+				o.logger.Warn("Dropping slow snapshot subscriber")
+				delete(o.snapshotSubscribers, updates)
+				close(updates)
+			}
+		}
+	}
+	if len(o.subscribers) == 0 {
+		return
+	}
+	state := o.state()
+	for updates := range o.subscribers {
+		select {
+		case updates <- state:
+		default:
+			o.logger.Warn("Dropping slow state stream client")
+			delete(o.subscribers, updates)
+			close(updates)
+		}
+	}
+}
+
+// Subscribe sends the current Snapshot, then a new one after every render,
+// until cancel is called. A subscriber that falls too far behind is dropped
+// and sees updates closed.
+func (o *ControllerObj) Subscribe() (updates <-chan []ResourceView, cancel func()) {
+	views := make(chan []ResourceView, streamBuffer)
+	o.resourceLock.Lock()
+	o.snapshotSubscribers[views] = struct{}{}
+	views <- o.snapshot()
+	o.resourceLock.Unlock()
+	return views, func() {
+		o.resourceLock.Lock()
+		defer o.resourceLock.Unlock()
+		if _, ok := o.snapshotSubscribers[views]; ok {
+			delete(o.snapshotSubscribers, views)
+			close(views)
+		}
+	}
+}
+
+// StreamHandler upgrades to a WebSocket and sends the same JSON document as
+// StateHandler after every render.
+func (o *ControllerObj) StreamHandler() http.Handler {
+	return websocket.Handler(func(ws *websocket.Conn) {
+		updates := make(chan boardState, streamBuffer)
+		o.resourceLock.Lock()
+		o.subscribers[updates] = struct{}{}
+		updates <- o.state()
+		o.resourceLock.Unlock()
+		defer func() {
+			o.resourceLock.Lock()
+			if _, ok := o.subscribers[updates]; ok {
+				delete(o.subscribers, updates)
+				close(updates)
+			}
+			o.resourceLock.Unlock()
+		}()
+		for state := range updates {
+			if err := ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+			if err := websocket.JSON.Send(ws, state); err != nil {
+				return
+			}
+		}
+	})
+}
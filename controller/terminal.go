@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// TerminalBlinkt is a BlinktDriver that draws the strip in a terminal with
+// ANSI colors, for development away from a Raspberry Pi.
+type TerminalBlinkt struct {
+	out    io.Writer
+	lock   sync.Mutex
+	colors []string
+	levels []float64
+}
+
+func NewTerminalBlinkt(out io.Writer, pixels int) *TerminalBlinkt {
+	t := &TerminalBlinkt{
+		out:    out,
+		colors: make([]string, pixels),
+		levels: make([]float64, pixels),
+	}
+	for i := range t.colors {
+		t.colors[i] = "000000"
+	}
+	return t
+}
+
+func (t *TerminalBlinkt) Set(pixel int, color string, brightness float64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if pixel >= 0 && pixel < len(t.colors) {
+		t.colors[pixel] = color
+		t.levels[pixel] = brightness
+	}
+	return nil
+}
+
+func (t *TerminalBlinkt) Show() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var line bytes.Buffer
+	line.WriteString("\r")
+	for i, color := range t.colors {
+		r, g, b, err := parseHex(color)
+		if err != nil {
+			r, g, b = 0, 0, 0
+		}
+		scale := func(c uint8) int {
+			return int(float64(c) * clampBrightness(t.levels[i]))
+		}
+		fmt.Fprintf(&line, "\x1b[38;2;%d;%d;%dm██\x1b[0m ", scale(r), scale(g), scale(b))
+	}
+	_, err := t.out.Write(line.Bytes())
+	return err
+}
+
+func (t *TerminalBlinkt) Cleanup(color string, brightness float64) error {
+	for i := range t.colors {
+		t.Set(i, color, brightness)
+	}
+	if err := t.Show(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(t.out)
+	return err
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/elafargue/blinkt"
+)
+
+// testPatternDwell is how long each LED shows each primary color.
+const testPatternDwell = 300 * time.Millisecond
+
+// TestPattern lights every LED in turn in red, green then blue at the
+// controller's brightness, so a technician can check the board, then draws
+// the resources again, or leaves the strip off while paused. Events wait
+// until it is done. It stops early with ctx's error if ctx is cancelled.
+func (o *ControllerObj) TestPattern(ctx context.Context) error {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	ticker := o.clock.NewTicker(testPatternDwell)
+	defer ticker.Stop()
+	for i := 0; i < o.ledCount; i++ {
+		o.set(i, blinkt.Off, 0)
+	}
+	err := o.walk(ctx, ticker)
+	for i := 0; i < o.ledCount; i++ {
+		o.set(i, blinkt.Off, 0)
+	}
+	o.show()
+	o.redraw()
+	return err
+}
+
+func (o *ControllerObj) walk(ctx context.Context, ticker Ticker) error {
+	for i := 0; i < o.ledCount; i++ {
+		for _, color := range []string{blinkt.Red, "00FF00", blinkt.Blue} {
+			o.set(i, color, o.brightness)
+			o.show()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.Chan():
+			}
+		}
+		o.set(i, blinkt.Off, 0)
+	}
+	return nil
+}
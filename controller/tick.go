@@ -0,0 +1,82 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+// TickFunc reports whether the color of obj may have changed with time
+// alone, and so should be worked out again on the next tick.
+type TickFunc func(obj interface{}) bool
+
+// tick works the color of watched resources out again every TickInterval,
+// independently of informer events.
+func (o *ControllerObj) tick(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(o.jittered(o.TickInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if o.syncing == 0 {
+				o.reevaluate(o.TickFunc)
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
+
+// SetColorFunc makes every watch color its objects with colorFunc from now
+// on, instead of the ColorFunc it was started with, and recolors the objects
+// already on the strip. A nil colorFunc goes back to each watch's own.
+func (o *ControllerObj) SetColorFunc(colorFunc ColorFunc) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	o.colorFunc = nil
+	if colorFunc != nil {
+		o.colorFunc = func(obj interface{}) (string, error) {
+			return colorFunc(obj), nil
+		}
+	}
+	o.reevaluate(nil)
+}
+
+// reevaluate runs every tracked object that pick returns true for, or all of
+// them if pick is nil, through its watch's functions again. Only the
+// resources whose color actually changed get re-rendered.
+func (o *ControllerObj) reevaluate(pick TickFunc) {
+	var objs []interface{}
+	var evaluators []func(obj interface{})
+	for _, r := range o.resourceList {
+		evaluate := o.evaluators[r.source]
+		if evaluate == nil || r.obj == nil || r.state == deleted {
+			continue
+		}
+		if pick != nil {
+			var picked bool
+			if err := safely("tick function", func() { picked = pick(r.obj) }); err != nil {
+				o.logger.Warn("Skipping resource on tick", "key", r.key, "error", err)
+				continue
+			}
+			if !picked {
+				continue
+			}
+		}
+		objs = append(objs, r.obj)
+		evaluators = append(evaluators, evaluate)
+	}
+	for i, obj := range objs {
+		evaluators[i](obj)
+	}
+}
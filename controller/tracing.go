@@ -0,0 +1,36 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/elafargue/blinkt-k8s-controller/controller"
+
+// startEvent starts the span of an added, updated or deleted resource. It
+// must be ended before rendering, so spans stay short and never cover the
+// strip being drawn.
+func (o *ControllerObj) startEvent(state int, key, color string) trace.Span {
+	_, span := o.tracer.Start(context.Background(), "blinkt."+stateNames[state], trace.WithAttributes(
+		attribute.String("blinkt.event", stateNames[state]),
+		attribute.String("blinkt.key", key),
+		attribute.String("blinkt.color", color),
+	))
+	return span
+}
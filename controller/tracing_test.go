@@ -0,0 +1,132 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elafargue/blinkt"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanRecorder is a TracerProvider that tracks which spans are open.
+type spanRecorder struct {
+	mu    sync.Mutex
+	open  map[*recordedSpan]bool
+	ended []string
+}
+
+func newSpanRecorder() *spanRecorder {
+	return &spanRecorder{open: map[*recordedSpan]bool{}}
+}
+
+func (p *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return p
+}
+
+func (p *spanRecorder) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := &recordedSpan{Span: trace.SpanFromContext(ctx), name: name, p: p}
+	p.open[s] = true
+	return ctx, s
+}
+
+// openSpans returns the names of the spans started and not yet ended.
+func (p *spanRecorder) openSpans() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var names []string
+	for s := range p.open {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+type recordedSpan struct {
+	trace.Span
+	name string
+	p    *spanRecorder
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+	delete(s.p.open, s)
+	s.p.ended = append(s.p.ended, s.name)
+}
+
+// spanCheckingDriver fails the test on any call made while a span is open.
+type spanCheckingDriver struct {
+	*fakeDriver
+	t     *testing.T
+	spans *spanRecorder
+}
+
+func (d *spanCheckingDriver) check(op string) {
+	if open := d.spans.openSpans(); len(open) > 0 {
+		d.t.Errorf("%s called with spans %v open", op, open)
+	}
+}
+
+func (d *spanCheckingDriver) Set(led int, color string, brightness float64) error {
+	d.check("Set")
+	return d.fakeDriver.Set(led, color, brightness)
+}
+
+func (d *spanCheckingDriver) Show() error {
+	d.check("Show")
+	return d.fakeDriver.Show()
+}
+
+func TestSpansEndBeforeDriverCalls(t *testing.T) {
+	spans := newSpanRecorder()
+	d := &spanCheckingDriver{newFakeDriver(maxLEDCount), t, spans}
+	o, err := newController(1, d, []Option{
+		WithClock(newFakeClock()),
+		WithLogOutput(ioutil.Discard),
+		WithFlash(2, time.Millisecond),
+		WithTransition(time.Millisecond),
+		WithMaxResources(1, EvictOldest),
+		WithTracerProvider(spans),
+	})
+	if err != nil {
+		t.Fatalf("newController: %v", err)
+	}
+	o.Add("a", blinkt.Red)
+	o.Add("b", blinkt.Blue) // evicts a, which flashes where it was
+	o.Add("b", blinkt.Red)
+	o.Delete("b")
+
+	if d.count("set") == 0 || d.count("show") == 0 {
+		t.Fatalf("the driver was never called: %v", d.calls)
+	}
+	counts := map[string]int{}
+	for _, name := range spans.ended {
+		counts[name]++
+	}
+	for name, want := range map[string]int{"blinkt.added": 2, "blinkt.updated": 1, "blinkt.deleted": 2} {
+		if counts[name] != want {
+			t.Errorf("ended %d %s spans, want %d", counts[name], name, want)
+		}
+	}
+	if counts["blinkt.render"] == 0 {
+		t.Errorf("no render span ended")
+	}
+}
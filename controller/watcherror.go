@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchErrorBlink is how long the strip shows ErrorColor, then the
+// resources, while a watch is failing.
+const watchErrorBlink = time.Second
+
+// guard wraps listWatch so the controller hears of every failed list or
+// watch of source, and of the first one that works again.
+func (o *ControllerObj) guard(source int, listWatch *cache.ListWatch) *cache.ListWatch {
+	guarded := *listWatch
+	guarded.ListFunc = func(options metav1.ListOptions) (runtime.Object, error) {
+		obj, err := listWatch.ListFunc(options)
+		o.watchResult(source, "list", err)
+		return obj, err
+	}
+	guarded.WatchFunc = func(options metav1.ListOptions) (watch.Interface, error) {
+		w, err := listWatch.WatchFunc(options)
+		o.watchResult(source, "watch", err)
+		return w, err
+	}
+	return &guarded
+}
+
+func (o *ControllerObj) watchResult(source int, op string, err error) {
+	o.resourceLock.Lock()
+	defer o.resourceLock.Unlock()
+	if err != nil {
+		o.logger.Warn("Watch failed", "op", op, "error", err)
+		o.metrics.watchErrors.WithLabelValues(op).Inc()
+		if o.watchErrors == nil {
+			o.watchErrors = map[int]error{}
+		}
+		o.watchErrors[source] = err
+		return
+	}
+	if _, failing := o.watchErrors[source]; !failing {
+		return
+	}
+	o.logger.Info("Watch recovered", "op", op)
+	delete(o.watchErrors, source)
+	if len(o.watchErrors) == 0 && o.errorShown {
+		o.errorShown = false
+		o.redraw()
+	}
+}
+
+// blinkWatchErrors alternates the whole strip between ErrorColor and the
+// resources while any watch is failing.
+func (o *ControllerObj) blinkWatchErrors(stopCh <-chan struct{}) {
+	ticker := o.clock.NewTicker(watchErrorBlink)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.Chan():
+			o.resourceLock.Lock()
+			if len(o.watchErrors) > 0 && !o.paused {
+				o.errorShown = !o.errorShown
+				if o.errorShown {
+					for i := 0; i < o.ledCount; i++ {
+						o.set(i, o.ErrorColor, o.brightness)
+					}
+					o.show()
+				} else {
+					o.redraw()
+				}
+			}
+			o.resourceLock.Unlock()
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2017 Apprenda, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGuardTracksWatchErrors(t *testing.T) {
+	o, _ := newTestController(t, WithFlash(0, 0), WithErrorColor("FF0000"))
+	var err error
+	lw := o.guard(0, &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &v1.PodList{}, err
+		},
+	})
+	failing := func() bool {
+		o.resourceLock.Lock()
+		defer o.resourceLock.Unlock()
+		return len(o.watchErrors) > 0
+	}
+
+	err = errors.New("forbidden")
+	lw.ListFunc(metav1.ListOptions{})
+	if !failing() {
+		t.Fatal("a failed list was not recorded")
+	}
+	err = nil
+	lw.ListFunc(metav1.ListOptions{})
+	if failing() {
+		t.Error("the watch is still failing after a list worked")
+	}
+}
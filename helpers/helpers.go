@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"strings"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -42,6 +43,17 @@ func NewClientsets() (*kubernetes.Clientset, *heapster.Clientset) {
 	return kubernetesClientset, heapsterClientset
 }
 
+// SplitList splits a comma-separated flag value, dropping empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func RatioToColor(target, actual int64) string {
 	ratio := math.Min(2, 2*float64(actual)/float64(target))
 	b := int(math.Max(0, 255*(1-ratio)))
@@ -16,10 +16,14 @@ package main
 
 import (
 	"flag"
+	"log"
+	"net"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/elafargue/blinkt"
+	"github.com/elafargue/blinkt-k8s-controller/blinktrpc"
 	"github.com/elafargue/blinkt-k8s-controller/controller"
 	"github.com/elafargue/blinkt-k8s-controller/helpers"
 
@@ -27,33 +31,137 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/tools/cache"
 )
 
 func main() {
 	brightness := flag.Float64("brightness", 0.25, "strip brightness")
+	ledCount := flag.Int("led_count", 8, "number of LEDs on the strip")
 	resyncPeriod := flag.Duration("resync_period", 5*time.Second, "resync period")
+	scrollInterval := flag.Duration("scroll_interval", 0, "interval between pages when there are more resources than LEDs (0 disables scrolling)")
 	namespace := flag.String("namespace", v1.NamespaceDefault, "namespace to monitor")
+	flashCount := flag.Int("flash_count", 2, "number of flashes when a resource changes (0 disables flashing)")
+	flashDuration := flag.Duration("flash_duration", 50*time.Millisecond, "duration of each flash")
+	stableSlots := flag.Bool("stable_slots", false, "keep each resource on the same LED for its lifetime")
+	groupByNamespace := flag.Bool("group_by_namespace", false, "keep the pods of each namespace together on the strip")
+	idleAnimation := flag.Bool("idle_animation", false, "play a rainbow sweep while there is nothing to show")
+	heartbeatSlot := flag.Int("heartbeat_slot", -1, "LED to reserve for a liveness heartbeat (-1 disables it)")
+	cleanupColor := flag.String("cleanup_color", blinkt.Red, "color to show when the controller exits")
+	overflowColor := flag.String("overflow_color", "", "color of the blinking last LED when there are more resources than LEDs (disabled when empty)")
+	refreshInterval := flag.Duration("refresh_interval", 0, "render at most once per interval (0 renders every event)")
+	transition := flag.Duration("transition", 0, "fade an LED to its new color over this duration instead of flashing (0 disables fading)")
+	aggregate := flag.Bool("aggregate", false, "share the LEDs between colors in proportion to how many resources have each color")
+	gamma := flag.Float64("gamma", 1, "gamma correction applied to brightness and colors (1 disables it)")
+	stateFile := flag.String("state_file", "", "file to save the LED state to and restore it from on startup")
+	configFile := flag.String("config", "", "JSON file to reload brightness, flash, refresh and cleanup settings from on SIGHUP")
+	pauseSignal := flag.Bool("pause_signal", false, "toggle pausing the display on SIGUSR1")
+	pauseBlank := flag.Bool("pause_blank", false, "turn the LEDs off while paused instead of freezing them")
+	alertColors := flag.String("alert_colors", "", "comma-separated colors whose LEDs keep blinking")
+	nightBrightness := flag.Float64("night_brightness", 1, "factor applied to the brightness between night_start and night_end")
+	nightStart := flag.String("night_start", "", "time of day (HH:MM) night dimming starts")
+	nightEnd := flag.String("night_end", "", "time of day (HH:MM) night dimming ends")
+	timezone := flag.String("timezone", "Local", "time zone of night_start and night_end")
+	repaintInterval := flag.Duration("repaint_interval", 0, "redraw the whole strip this often even when nothing changed (0 disables it)")
+	startupAnimation := flag.Bool("startup_animation", false, "sweep across every LED on startup")
+	blankOnCleanup := flag.Bool("blank_on_cleanup", false, "turn the LEDs off on shutdown instead of showing cleanup_color")
+	minFlashInterval := flag.Duration("min_flash_interval", 0, "minimum time between two flashes of the same resource")
+	blendOverflow := flag.Bool("blend_overflow", false, "in aggregate mode, blend colors together when there are more colors than LEDs")
+	dryRun := flag.Bool("dry_run", false, "log what the LEDs would show instead of driving them")
+	maxResources := flag.Int("max_resources", 0, "maximum number of resources to track, evicting the oldest beyond it (0 is unlimited)")
+	evictByPriority := flag.Bool("evict_by_priority", false, "evict the lowest priority resources first instead of the oldest")
+	resyncJitter := flag.Float64("resync_jitter", 0, "stretch the resync, refresh and repaint periods by a random fraction of up to this much")
+	pulseRestarts := flag.Bool("pulse_restarts", false, "pulse the LEDs of pods faster the more they restarted")
+	skipUnchanged := flag.Bool("skip_unchanged", false, "only write the LEDs that changed to the strip")
+	reverse := flag.Bool("reverse", false, "number the LEDs from the other end, for a board mounted upside down")
+	grpcAddress := flag.String("grpc_address", "", "address to serve the Board gRPC service on (disabled when empty)")
+	flashColor := flag.String("flash_color", "", "color to flash changed resources in (defaults to their own color)")
+	deleteLinger := flag.Duration("delete_linger", 0, "how long a deleted resource stays lit in delete_color")
+	deleteColor := flag.String("delete_color", "", "color of deleted resources while they linger (defaults to red)")
+	sortByActivity := flag.Bool("sort_by_activity", false, "show the most recently changed resources first")
+	errorColor := flag.String("error_color", "", "color the strip blinks in while watching the cluster fails, such as FF0000 (disabled when empty)")
+	listenAddress := flag.String("listen_address", "", "address to serve /metrics, /state and /stream on (disabled when empty)")
 	flag.Parse()
 	nodeName := os.Getenv("NODE_NAME")
-	c := controller.NewController(*brightness)
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	eviction := controller.EvictOldest
+	if *evictByPriority {
+		eviction = controller.EvictLowestPriority
+	}
+	var pulseRate controller.PulseRateFunc
+	if *pulseRestarts {
+		pulseRate = controller.PodRestartPulseRate
+	}
+	c, err := controller.NewControllerE(
+		*brightness,
+		controller.WithLEDCount(*ledCount),
+		controller.WithScrollInterval(*scrollInterval),
+		controller.WithStableSlots(*stableSlots),
+		controller.WithGroupByNamespace(*groupByNamespace),
+		controller.WithFlash(*flashCount, *flashDuration),
+		controller.WithFlashColor(*flashColor),
+		controller.WithDeleteLinger(*deleteLinger, *deleteColor),
+		controller.WithSortByActivity(*sortByActivity),
+		controller.WithErrorColor(*errorColor),
+		controller.WithIdleAnimation(*idleAnimation),
+		controller.WithHeartbeat(*heartbeatSlot, "00FF00"),
+		controller.WithCleanupColor(*cleanupColor),
+		controller.WithOverflowColor(*overflowColor),
+		controller.WithRefreshInterval(*refreshInterval),
+		controller.WithTransition(*transition),
+		controller.WithAggregateMode(*aggregate),
+		controller.WithBlendOverflow(*blendOverflow),
+		controller.WithGamma(*gamma),
+		controller.WithStateFile(*stateFile),
+		controller.WithConfigFile(*configFile),
+		controller.WithPauseSignal(*pauseSignal),
+		controller.WithPauseBlank(*pauseBlank),
+		controller.WithAlertColors(helpers.SplitList(*alertColors)...),
+		controller.WithNightDimming(*nightBrightness, *nightStart, *nightEnd),
+		controller.WithLocation(location),
+		controller.WithRepaintInterval(*repaintInterval),
+		controller.WithStartupAnimation(*startupAnimation),
+		controller.WithBlankOnCleanup(*blankOnCleanup),
+		controller.WithMinFlashInterval(*minFlashInterval),
+		controller.WithDryRun(*dryRun),
+		controller.WithMaxResources(*maxResources, eviction),
+		controller.WithResyncJitter(*resyncJitter),
+		controller.WithSkipUnchanged(*skipUnchanged),
+		controller.WithReverse(*reverse),
+		controller.WithPulseRateFunc(pulseRate),
+	)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
 	defer c.Cleanup()
+	if *listenAddress != "" {
+		http.Handle("/metrics", c.MetricsHandler())
+		http.Handle("/state", c.StateHandler())
+		http.Handle("/stream", c.StreamHandler())
+		http.Handle("/healthz", c.HealthHandler())
+		http.Handle("/legend", c.LegendHandler())
+		go func() {
+			log.Println(http.ListenAndServe(*listenAddress, nil))
+		}()
+	}
+	if *grpcAddress != "" {
+		listener, err := net.Listen("tcp", *grpcAddress)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		server := blinktrpc.Register(c)
+		defer server.Stop()
+		go func() {
+			log.Println(server.Serve(listener))
+		}()
+	}
 	kubernetesClientset, heapsterClientset := helpers.NewClientsets()
 	c.Watch(
-		&cache.ListWatch{
-			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-				options.LabelSelector = labels.Set{"blinktShow": "true"}.String()
-				options.FieldSelector = fields.Set{"spec.nodeName": nodeName}.String()
-				return kubernetesClientset.CoreV1().Pods(*namespace).List(options)
-			},
-			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-				options.LabelSelector = labels.Set{"blinktShow": "true"}.String()
-				options.FieldSelector = fields.Set{"spec.nodeName": nodeName}.String()
-				return kubernetesClientset.CoreV1().Pods(*namespace).Watch(options)
-			},
-		},
+		controller.NewPodListWatch(kubernetesClientset, *namespace, func(options *metav1.ListOptions) {
+			options.LabelSelector = labels.Set{"blinktShow": "true"}.String()
+			options.FieldSelector = fields.Set{"spec.nodeName": nodeName}.String()
+		}),
 		&v1.Pod{},
 		*resyncPeriod,
 		func(obj interface{}) string {
@@ -72,5 +180,6 @@ func main() {
 				color = helpers.RatioToColor(cpuRequested, cpuUsed)
 			}
 			return color
-		})
+		},
+		c.HandleSignals())
 }